@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// projectAddCmd groups the commands adding parts to an existing project
+var projectAddCmd = &cobra.Command{
+	Use:               "add",
+	Short:             "Add a part to the project",
+	Long:              `Add a part, like an entrypoint, to the project generated by init the working directory belongs to.`,
+	PersistentPreRunE: enterProjectRoot,
+}
+
+func init() {
+	rootCmd.AddCommand(projectAddCmd)
+}
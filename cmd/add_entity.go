@@ -0,0 +1,390 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// domainDirectory holds a package per entity of the project
+const domainDirectory = "pkg/domain"
+
+var entityFields []string
+
+var entityNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// entityFiles are the files of an entity's package by their name. The fields of the entity are added to its
+// struct and to the sample of its tests afterwards, the way add field does.
+var entityFiles = map[string]*template.Template{
+	"entity.go": template.Must(template.New("entity").Parse(`package {{.Package}}
+
+// {{.Type}} is an entity of the domain, identified by its ID
+type {{.Type}} struct {
+	ID string
+}
+`)),
+	"repository.go": template.Must(template.New("repository").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned for IDs no {{.Type}} has
+	ErrNotFound = errors.New("{{.Package}} not found")
+	// ErrExists is returned when creating a {{.Type}} with the ID of another
+	ErrExists = errors.New("{{.Package}} already exists")
+)
+
+// Repository stores the {{.Type}} entities
+type Repository interface {
+	// Create stores a new {{.Type}}, giving it an ID unless it has one
+	Create(ctx context.Context, entity *{{.Type}}) error
+	Get(ctx context.Context, id string) (*{{.Type}}, error)
+	List(ctx context.Context) ([]*{{.Type}}, error)
+	// Update replaces the stored {{.Type}} with the same ID
+	Update(ctx context.Context, entity *{{.Type}}) error
+	Delete(ctx context.Context, id string) error
+}
+
+// NewID returns a random ID for a new {{.Type}}
+func NewID() string {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id)
+}
+`)),
+	"memory.go": template.Must(template.New("memory").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryRepository keeps the {{.Type}} entities in memory, for tests and prototypes. It's safe for concurrent
+// use. It stores and returns copies of the entities, their slices and maps being shared.
+type MemoryRepository struct {
+	mu       sync.RWMutex
+	entities map[string]{{.Type}}
+}
+
+var _ Repository = (*MemoryRepository)(nil)
+
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{entities: map[string]{{.Type}}{}}
+}
+
+func (r *MemoryRepository) Create(ctx context.Context, entity *{{.Type}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if entity.ID == "" {
+		entity.ID = NewID()
+	}
+	if _, ok := r.entities[entity.ID]; ok {
+		return ErrExists
+	}
+	r.entities[entity.ID] = *entity
+	return nil
+}
+
+func (r *MemoryRepository) Get(ctx context.Context, id string) (*{{.Type}}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entity, ok := r.entities[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &entity, nil
+}
+
+// List returns the entities sorted by ID
+func (r *MemoryRepository) List(ctx context.Context) ([]*{{.Type}}, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entities := make([]*{{.Type}}, 0, len(r.entities))
+	for _, entity := range r.entities {
+		entity := entity
+		entities = append(entities, &entity)
+	}
+	sort.Slice(entities, func(i, j int) bool {
+		return entities[i].ID < entities[j].ID
+	})
+	return entities, nil
+}
+
+func (r *MemoryRepository) Update(ctx context.Context, entity *{{.Type}}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entities[entity.ID]; !ok {
+		return ErrNotFound
+	}
+	r.entities[entity.ID] = *entity
+	return nil
+}
+
+func (r *MemoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entities[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.entities, id)
+	return nil
+}
+`)),
+	"repository_test.go": template.Must(template.New("repositoryTest").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// sample{{.Type}} returns an entity without ID and with a value in its fields
+func sample{{.Type}}() {{.Type}} {
+	var entity {{.Type}}
+	return entity
+}
+
+func TestMemoryRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		return NewMemoryRepository()
+	})
+}
+
+// testRepository runs the tests every implementation of Repository passes, each on a new empty repository
+func testRepository(t *testing.T, newRepository func(t *testing.T) Repository) {
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		test func(t *testing.T, repository Repository)
+	}{
+		{"create gives an ID", func(t *testing.T, repository Repository) {
+			entity := sample{{.Type}}()
+			mustCreate(t, repository, &entity)
+			if entity.ID == "" {
+				t.Fatal("created entity has no ID")
+			}
+			assertStored(t, repository, &entity)
+		}},
+		{"create keeps the ID", func(t *testing.T, repository Repository) {
+			entity := sample{{.Type}}()
+			entity.ID = "first"
+			mustCreate(t, repository, &entity)
+			assertStored(t, repository, &entity)
+		}},
+		{"create existing", func(t *testing.T, repository Repository) {
+			entity := sample{{.Type}}()
+			mustCreate(t, repository, &entity)
+			err := repository.Create(ctx, &entity)
+			if !errors.Is(err, ErrExists) {
+				t.Fatalf("Create() error = %v, want %v", err, ErrExists)
+			}
+		}},
+		{"get missing", func(t *testing.T, repository Repository) {
+			_, err := repository.Get(ctx, "missing")
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get() error = %v, want %v", err, ErrNotFound)
+			}
+		}},
+		{"list", func(t *testing.T, repository Repository) {
+			first, second := sample{{.Type}}(), sample{{.Type}}()
+			mustCreate(t, repository, &first)
+			mustCreate(t, repository, &second)
+			entities, err := repository.List(ctx)
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			ids := map[string]bool{}
+			for _, entity := range entities {
+				ids[entity.ID] = true
+			}
+			if len(entities) != 2 || !ids[first.ID] || !ids[second.ID] {
+				t.Fatalf("List() = %v, want the entities %s and %s", entities, first.ID, second.ID)
+			}
+		}},
+		{"update", func(t *testing.T, repository Repository) {
+			entity := {{.Type}}{}
+			mustCreate(t, repository, &entity)
+			updated := sample{{.Type}}()
+			updated.ID = entity.ID
+			err := repository.Update(ctx, &updated)
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			assertStored(t, repository, &updated)
+		}},
+		{"update missing", func(t *testing.T, repository Repository) {
+			entity := sample{{.Type}}()
+			entity.ID = "missing"
+			err := repository.Update(ctx, &entity)
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Update() error = %v, want %v", err, ErrNotFound)
+			}
+		}},
+		{"delete", func(t *testing.T, repository Repository) {
+			entity := sample{{.Type}}()
+			mustCreate(t, repository, &entity)
+			err := repository.Delete(ctx, entity.ID)
+			if err != nil {
+				t.Fatalf("Delete() error = %v", err)
+			}
+			_, err = repository.Get(ctx, entity.ID)
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Get() after Delete() error = %v, want %v", err, ErrNotFound)
+			}
+		}},
+		{"delete missing", func(t *testing.T, repository Repository) {
+			err := repository.Delete(ctx, "missing")
+			if !errors.Is(err, ErrNotFound) {
+				t.Fatalf("Delete() error = %v, want %v", err, ErrNotFound)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+func mustCreate(t *testing.T, repository Repository, entity *{{.Type}}) {
+	t.Helper()
+	err := repository.Create(context.Background(), entity)
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+}
+
+func assertStored(t *testing.T, repository Repository, want *{{.Type}}) {
+	t.Helper()
+	got, err := repository.Get(context.Background(), want.ID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Get() = %+v, want %+v", got, want)
+	}
+}
+`)),
+}
+
+// addEntityCmd represents the add entity command
+var addEntityCmd = &cobra.Command{
+	Use:   "entity [name]",
+	Short: "Add a domain entity with its repository",
+	Long: `Add a domain entity to pkg/domain/<name>: its struct, a Repository interface with the CRUD
+methods, a thread-safe in-memory implementation of it and tests every implementation passes.
+The fields are given as --field name:type, like --field total:float64 --field tags:[]string,
+and can be added later with add field.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fields, err := parseEntityFields(entityFields)
+		if err != nil {
+			return err
+		}
+		return addEntity(args[0], fields)
+	},
+}
+
+// parseEntityFields splits the name:type values of --field and checks them
+func parseEntityFields(values []string) ([][2]string, error) {
+	fields := make([][2]string, 0, len(values))
+	for _, value := range values {
+		name, typeName, found := strings.Cut(value, ":")
+		if !found || name == "" || typeName == "" {
+			return nil, fmt.Errorf("invalid field %q, expected name:type", value)
+		}
+		err := checkEntityField(name, typeName)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, [2]string{name, typeName})
+	}
+	return fields, nil
+}
+
+// checkEntityName fails unless the name makes the name of a package, the entity's or the resource's
+func checkEntityName(kind string, name string) error {
+	if !entityNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid %s name %q, use letters, digits, - and _", kind, name)
+	}
+	if token.IsKeyword(entityPackageName(name)) {
+		return fmt.Errorf("invalid %s name %q, its package would be named after a Go keyword", kind, name)
+	}
+	return nil
+}
+
+// addEntity generates the package of the entity, then adds its fields
+func addEntity(name string, fields [][2]string) error {
+	err := checkEntityName("entity", name)
+	if err != nil {
+		return err
+	}
+	directory := entityDirectory(name)
+	if _, err := os.Stat(directory); err == nil {
+		return fmt.Errorf("entity %s already exists in %s", name, directory)
+	}
+	data := map[string]string{"Package": entityPackageName(name), "Type": entityTypeName(name)}
+	for fileName, source := range entityFiles {
+		var code bytes.Buffer
+		err = source.Execute(&code, data)
+		if err != nil {
+			return err
+		}
+		formattedCode, err := format.Source(code.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to format code: %w", err)
+		}
+		err = writeProjectFile(filepath.Join(directory, fileName), formattedCode)
+		if err != nil {
+			return err
+		}
+	}
+	for _, field := range fields {
+		err = addEntityField(name, field[0], field[1])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// entityTypeName turns the name of the entity into the name of its struct: order_item is OrderItem
+func entityTypeName(name string) string {
+	var typeName strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		typeName.WriteString(fieldName(part))
+	}
+	return typeName.String()
+}
+
+// entityPackageName turns the name of the entity into the name of its package: order_item is orderitem
+func entityPackageName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+func entityDirectory(name string) string {
+	return filepath.Join(domainDirectory, entityPackageName(name))
+}
+
+func init() {
+	projectAddCmd.AddCommand(addEntityCmd)
+	addEntityCmd.Flags().StringArrayVarP(&entityFields, "field", "f", nil, "Field of the entity as name:type, repeated for each field")
+}
@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var entrypointType string
+var entrypointFramework string
+
+var entrypointNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// addEntrypointCmd represents the add entrypoint command
+var addEntrypointCmd = &cobra.Command{
+	Use:   "entrypoint [name]",
+	Short: "Add a binary to the project",
+	Long: `Add a binary to the project, built from cmd/<name>/main.go. The entrypoint loads the config like
+the ones init generates and is recorded in the project's manifest. Api entrypoints use the HTTP
+framework given with --framework, or the one of the project's other api entrypoints.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addEntrypoint(strings.ToLower(args[0]), entrypointType, entrypointFramework)
+	},
+}
+
+func addEntrypoint(name string, appType string, framework string) error {
+	if !entrypointNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid entrypoint name %q, use lowercase letters, digits, - and _", name)
+	}
+	_, err := resolveApplicationType(appType)
+	if err != nil {
+		return err
+	}
+	if entrypointExists(name) {
+		return fmt.Errorf("entrypoint %s already exists", name)
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	if manifest.ProjectType == projectTypeLibrary {
+		return fmt.Errorf("the project is a %s, it has no entrypoints", projectTypeLibrary)
+	}
+	for _, entrypoint := range manifest.Entrypoints {
+		if entrypoint.Name == name {
+			return fmt.Errorf("entrypoint %s is in the manifest already", name)
+		}
+	}
+	if appType == applicationTypeApi {
+		framework = projectFramework(manifest, framework)
+		if !httpFrameworks[framework] {
+			return fmt.Errorf("unsupported HTTP framework %q, expected gin, echo or std", framework)
+		}
+	}
+	module, err := projectModulePath()
+	if err != nil {
+		return err
+	}
+	err = initializeEntrypoint(name, appType, framework, module)
+	if err != nil {
+		return err
+	}
+	err = finishEntrypoint(name, appType, framework, module)
+	if err != nil {
+		return err
+	}
+	err = loadConfigInEntrypoint(module, name)
+	if err != nil {
+		return err
+	}
+	manifest.Entrypoints = append(manifest.Entrypoints, newManifestEntrypoint(name, appType, framework))
+	return writeManifest(manifest)
+}
+
+// projectFramework returns the framework asked for, or else the one of the project's api entrypoints
+func projectFramework(manifest *projectManifest, framework string) string {
+	if framework != "" {
+		return framework
+	}
+	for _, entrypoint := range manifest.Entrypoints {
+		if entrypoint.Framework != "" {
+			return entrypoint.Framework
+		}
+	}
+	return "gin"
+}
+
+func init() {
+	projectAddCmd.AddCommand(addEntrypointCmd)
+	addEntrypointCmd.Flags().StringVarP(&entrypointType, "type", "t", applicationTypeCli, fmt.Sprintf("Type of the entrypoint (%s)", strings.Join(applicationTypeNames(), ", ")))
+	addEntrypointCmd.Flags().StringVar(&entrypointFramework, "framework", "", "HTTP framework of an api entrypoint (gin, echo, std)")
+	addEntrypointCmd.RegisterFlagCompletionFunc("type", completeApplicationTypes)
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var entityFieldPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+// addFieldCmd represents the add field command
+var addFieldCmd = &cobra.Command{
+	Use:   "field [entity] [name] [type]",
+	Short: "Add a field to a domain entity",
+	Long: `Add a field to the struct of a domain entity generated by add entity. The sample entity of its
+repository tests gets a value in the field when the type has a literal, like strings, numbers,
+booleans, time.Duration and time.Time. The SQL repository of the entity, if any, is regenerated
+and a migration adds the column of the field.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addEntityField(args[0], args[1], args[2])
+	},
+}
+
+// checkEntityField fails unless the name and the type make a field of an entity
+func checkEntityField(name string, typeName string) error {
+	if !entityFieldPattern.MatchString(name) {
+		return fmt.Errorf("invalid field name %q, use letters, digits and _", name)
+	}
+	if entityTypeName(name) == "ID" || strings.EqualFold(name, "id") {
+		return fmt.Errorf("every entity has the field ID already")
+	}
+	return checkTypeName(typeName)
+}
+
+// addEntityField adds the field to the entity's struct and a value of it to the sample of its tests
+func addEntityField(entity string, name string, typeName string) error {
+	err := checkEntityField(name, typeName)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(entityDirectory(entity), "entity.go")
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("the entity %s doesn't exist", entity)
+	}
+	source, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	structType := findStruct(source.file, entityTypeName(entity))
+	if structType == nil {
+		return fmt.Errorf("struct %s not found in %s", entityTypeName(entity), path)
+	}
+	field := entityTypeName(name)
+	if findField(structType, field) != nil {
+		return fmt.Errorf("the entity %s has the field %s already", entity, field)
+	}
+	structType.Fields.List = append(structType.Fields.List, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(field)},
+		Type:  ast.NewIdent(typeName),
+	})
+	addTypeImports(source.fset, source.file, typeName)
+	err = source.write()
+	if err != nil {
+		return err
+	}
+	err = updateSQLRepository(entity, field, typeName)
+	if err != nil {
+		return err
+	}
+	literal, ok := sampleLiteral(name, typeName)
+	if !ok {
+		return nil
+	}
+	return addSampleValue(entity, field, literal)
+}
+
+// addSampleValue sets the field of the entity returned by the sample function of the tests
+func addSampleValue(entity string, field string, literal string) error {
+	path := filepath.Join(entityDirectory(entity), "repository_test.go")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+	source, err := parseGoSource(path)
+	if err != nil {
+		return err
+	}
+	var sample *ast.FuncDecl
+	for _, decl := range source.file.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && function.Name.Name == "sample"+entityTypeName(entity) && function.Body != nil {
+			sample = function
+		}
+	}
+	if sample == nil || len(sample.Body.List) == 0 {
+		return nil
+	}
+	// the value is set right before the function returns the sample
+	statements := sample.Body.List
+	assignment := &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.SelectorExpr{X: ast.NewIdent("entity"), Sel: ast.NewIdent(field)}},
+		Tok: token.ASSIGN,
+		Rhs: []ast.Expr{ast.NewIdent(literal)},
+	}
+	sample.Body.List = append(append(statements[:len(statements)-1:len(statements)-1], assignment), statements[len(statements)-1])
+	addTypeImports(source.fset, source.file, literal)
+	return source.write()
+}
+
+// sampleLiteral returns a Go literal of the type that isn't its zero value, false for types without one
+func sampleLiteral(name string, typeName string) (string, bool) {
+	switch {
+	case typeName == "string":
+		return strconv.Quote(name), true
+	case typeName == "bool":
+		return "true", true
+	case strings.HasPrefix(typeName, "float"):
+		return "1.5", true
+	case strings.HasPrefix(typeName, "int"), strings.HasPrefix(typeName, "uint"), typeName == "byte", typeName == "rune":
+		return "7", true
+	case typeName == "time.Duration":
+		return "time.Second", true
+	case typeName == "time.Time":
+		return "time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)", true
+	case typeName == "[]byte":
+		return fmt.Sprintf("[]byte(%q)", name), true
+	case typeName == "[]string":
+		return fmt.Sprintf("[]string{%q}", name), true
+	}
+	return "", false
+}
+
+// findStruct returns the struct type declared with the name in the file, nil if there's none
+func findStruct(file *ast.File, name string) *ast.StructType {
+	var structType *ast.StructType
+	ast.Inspect(file, func(node ast.Node) bool {
+		if typeSpec, ok := node.(*ast.TypeSpec); ok && typeSpec.Name.Name == name {
+			structType, _ = typeSpec.Type.(*ast.StructType)
+		}
+		return structType == nil
+	})
+	return structType
+}
+
+func init() {
+	projectAddCmd.AddCommand(addFieldCmd)
+}
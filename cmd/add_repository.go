@@ -0,0 +1,671 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	sqliteModule            = "modernc.org/sqlite"
+	databaseCategory        = "database"
+	sqlRepositoryFile       = "sql.go"
+	sqlRepositoryTest       = "sql_test.go"
+	sqlColumnText           = "text"
+	sqlColumnBool           = "bool"
+	sqlColumnInteger        = "integer"
+	sqlColumnFloat          = "float"
+	sqlColumnBytes          = "bytes"
+	sqlColumnTime           = "time"
+	sqlColumnJson           = "json"
+	sqlColumnIdentifier     = "id"
+	defaultRepositoryDriver = "postgres"
+)
+
+var repositoryDriver string
+
+// sqlDriver is a database the SQL repositories can be generated for
+type sqlDriver struct {
+	// module provides the database/sql driver registered as the driver's name
+	module string
+	// dsn is the default data source name of the project's database, formatted with the project's name
+	dsn string
+	// quote quotes identifiers, numbered tells whether placeholders are numbered like $1
+	quote    string
+	numbered bool
+	// columnTypes are the SQL types of the kinds of columns
+	columnTypes map[string]string
+}
+
+// sqlDrivers are the drivers by their database/sql name. The generated schemas also run on SQLite, which
+// accepts the types and the quoting of the others, so every repository is tested against it.
+var sqlDrivers = map[string]sqlDriver{
+	"postgres": {
+		module: "github.com/lib/pq",
+		dsn:    "postgres://localhost:5432/%s?sslmode=disable",
+		quote:  `"`, numbered: true,
+		columnTypes: map[string]string{
+			sqlColumnIdentifier: "TEXT", sqlColumnText: "TEXT", sqlColumnBool: "BOOLEAN", sqlColumnInteger: "BIGINT",
+			sqlColumnFloat: "DOUBLE PRECISION", sqlColumnBytes: "BYTEA", sqlColumnTime: "TIMESTAMPTZ", sqlColumnJson: "JSONB",
+		},
+	},
+	"mysql": {
+		module: "github.com/go-sql-driver/mysql",
+		dsn:    "root@tcp(localhost:3306)/%s?parseTime=true",
+		quote:  "`",
+		columnTypes: map[string]string{
+			sqlColumnIdentifier: "VARCHAR(64)", sqlColumnText: "TEXT", sqlColumnBool: "BOOLEAN", sqlColumnInteger: "BIGINT",
+			sqlColumnFloat: "DOUBLE", sqlColumnBytes: "BLOB", sqlColumnTime: "DATETIME(6)", sqlColumnJson: "JSON",
+		},
+	},
+	"sqlite": {
+		module: sqliteModule,
+		dsn:    "%s.db",
+		quote:  `"`,
+		columnTypes: map[string]string{
+			sqlColumnIdentifier: "TEXT", sqlColumnText: "TEXT", sqlColumnBool: "BOOLEAN", sqlColumnInteger: "INTEGER",
+			sqlColumnFloat: "REAL", sqlColumnBytes: "BLOB", sqlColumnTime: "DATETIME", sqlColumnJson: "TEXT",
+		},
+	},
+}
+
+func sqlDriverNames() []string {
+	names := make([]string, 0, len(sqlDrivers))
+	for name := range sqlDrivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (d sqlDriver) quoteIdentifier(name string) string {
+	return d.quote + name + d.quote
+}
+
+func (d sqlDriver) placeholder(position int) string {
+	if d.numbered {
+		return fmt.Sprintf("$%d", position)
+	}
+	return "?"
+}
+
+// scalarColumnKinds are the kinds of the column of the Go types database/sql scans and stores as they are
+var scalarColumnKinds = map[string]string{
+	"string": sqlColumnText, "bool": sqlColumnBool, "[]byte": sqlColumnBytes, "time.Duration": sqlColumnInteger,
+	"float32": sqlColumnFloat, "float64": sqlColumnFloat,
+	"int": sqlColumnInteger, "int8": sqlColumnInteger, "int16": sqlColumnInteger, "int32": sqlColumnInteger, "int64": sqlColumnInteger,
+	"uint": sqlColumnInteger, "uint8": sqlColumnInteger, "uint16": sqlColumnInteger, "uint32": sqlColumnInteger, "uint64": sqlColumnInteger,
+	"byte": sqlColumnInteger, "rune": sqlColumnInteger,
+}
+
+// sqlColumn is the column of a field of an entity, with the Go expressions scanning it and storing it
+type sqlColumn struct {
+	Name  string
+	Type  string
+	Null  bool
+	Scan  string
+	Value string
+}
+
+// newSQLColumn maps the field to its column. Pointers make nullable columns, slices and maps other than
+// []byte are stored as JSON.
+func newSQLColumn(driver sqlDriver, field string, typeName string) sqlColumn {
+	column := sqlColumn{Name: columnName(field), Scan: "&entity." + field, Value: "entity." + field}
+	elementType := strings.TrimPrefix(typeName, "*")
+	column.Null = elementType != typeName
+	kind, scalar := scalarColumnKinds[elementType]
+	switch {
+	case elementType == "time.Time":
+		kind = sqlColumnTime
+		column.Scan = fmt.Sprintf("timeColumn[%s]{&entity.%s}", typeName, field)
+		column.Value = column.Scan
+	case !scalar || column.Null && kind == sqlColumnBytes:
+		kind = sqlColumnJson
+		column.Null = false
+		column.Scan = fmt.Sprintf("jsonColumn{&entity.%s}", field)
+		column.Value = column.Scan
+	case !column.Null:
+		// columns added to a table holding rows are nullable, NULL scans as the zero value
+		column.Scan = fmt.Sprintf("nullColumn[%s]{&entity.%s}", typeName, field)
+	}
+	column.Type = driver.columnTypes[kind]
+	return column
+}
+
+// columnName turns the name of a field into the name of its column: CreatedAt is created_at
+func columnName(field string) string {
+	var name strings.Builder
+	runes := []rune(field)
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToLower(r))
+	}
+	return name.String()
+}
+
+// tableName turns the name of the entity's struct into the plural name of its table: OrderItem is order_items
+func tableName(typeName string) string {
+	name := columnName(typeName)
+	switch {
+	case strings.HasSuffix(name, "y") && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return strings.TrimSuffix(name, "y") + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// sqlRepository implements the entity's Repository with database/sql. It's regenerated from the fields of
+// the entity, the queries are built by the generator.
+var sqlRepository = template.Must(template.New("sqlRepository").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	insertQuery = {{.Insert}}
+	selectQuery = {{.Select}}
+	getQuery    = {{.Get}}
+	listQuery   = {{.List}}
+	updateQuery = {{.Update}}
+	deleteQuery = {{.Delete}}
+	existsQuery = {{.Exists}}
+)
+
+// SQLRepository stores the {{.Type}} entities in the {{.Table}} table of a {{.Driver}} database. add field
+// regenerates it with the fields of {{.Type}}.
+type SQLRepository struct {
+	db *sql.DB
+}
+
+var _ Repository = (*SQLRepository)(nil)
+
+func NewSQLRepository(db *sql.DB) *SQLRepository {
+	return &SQLRepository{db: db}
+}
+
+func (r *SQLRepository) Create(ctx context.Context, entity *{{.Type}}) error {
+	if entity.ID == "" {
+		entity.ID = NewID()
+	}
+	_, err := r.db.ExecContext(ctx, insertQuery, values(entity)...)
+	if err != nil {
+		if exists, existsErr := r.exists(ctx, entity.ID); existsErr == nil && exists {
+			return ErrExists
+		}
+		return fmt.Errorf("failed to create {{.Package}}: %w", err)
+	}
+	return nil
+}
+
+func (r *SQLRepository) Get(ctx context.Context, id string) (*{{.Type}}, error) {
+	entity, err := scan(r.db.QueryRowContext(ctx, getQuery, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get {{.Package}}: %w", err)
+	}
+	return entity, nil
+}
+
+// List returns the entities sorted by ID
+func (r *SQLRepository) List(ctx context.Context) ([]*{{.Type}}, error) {
+	rows, err := r.db.QueryContext(ctx, listQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list {{.Package}}: %w", err)
+	}
+	defer rows.Close()
+	entities := []*{{.Type}}{}
+	for rows.Next() {
+		entity, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list {{.Package}}: %w", err)
+		}
+		entities = append(entities, entity)
+	}
+	return entities, rows.Err()
+}
+
+func (r *SQLRepository) Update(ctx context.Context, entity *{{.Type}}) error {
+	result, err := r.db.ExecContext(ctx, updateQuery, append(values(entity)[1:], entity.ID)...)
+	if err != nil {
+		return fmt.Errorf("failed to update {{.Package}}: %w", err)
+	}
+	// databases like MySQL don't count the rows an update leaves as they are
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		exists, err := r.exists(ctx, entity.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update {{.Package}}: %w", err)
+		}
+		if !exists {
+			return ErrNotFound
+		}
+	}
+	return nil
+}
+
+func (r *SQLRepository) Delete(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, deleteQuery, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete {{.Package}}: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SQLRepository) exists(ctx context.Context, id string) (bool, error) {
+	var found int
+	err := r.db.QueryRowContext(ctx, existsQuery, id).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// row is a row of QueryRow or of Query
+type row interface {
+	Scan(dest ...any) error
+}
+
+// scan reads the columns of selectQuery
+func scan(row row) (*{{.Type}}, error) {
+	var entity {{.Type}}
+	err := row.Scan(
+		&entity.ID,{{range .Columns}}
+		{{.Scan}},{{end}}
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &entity, nil
+}
+
+// values returns the values of the columns of insertQuery
+func values(entity *{{.Type}}) []any {
+	return []any{
+		entity.ID,{{range .Columns}}
+		{{.Value}},{{end}}
+	}
+}
+
+// nullColumn scans a nullable column into a field, NULL being its zero value
+type nullColumn[T any] struct {
+	field *T
+}
+
+func (c nullColumn[T]) Scan(src any) error {
+	var value sql.Null[T]
+	err := value.Scan(src)
+	*c.field = value.V
+	return err
+}
+
+// timeColumn stores a time.Time or *time.Time field in UTC and scans it from the drivers returning times as
+// text, like SQLite for the types it doesn't know
+type timeColumn[T time.Time | *time.Time] struct {
+	field *T
+}
+
+var timeLayouts = []string{"2006-01-02 15:04:05.999999999 -0700 MST", "2006-01-02 15:04:05.999999999-07:00", time.RFC3339Nano, "2006-01-02 15:04:05.999999999"}
+
+func (c timeColumn[T]) Value() (driver.Value, error) {
+	switch field := any(c.field).(type) {
+	case *time.Time:
+		return field.UTC(), nil
+	case **time.Time:
+		if *field == nil {
+			return nil, nil
+		}
+		return (*field).UTC(), nil
+	}
+	return nil, nil
+}
+
+func (c timeColumn[T]) Scan(src any) error {
+	var value time.Time
+	switch src := src.(type) {
+	case nil:
+		if field, ok := any(c.field).(**time.Time); ok {
+			*field = nil
+		}
+		return nil
+	case time.Time:
+		value = src
+	case string, []byte:
+		var err error
+		for _, layout := range timeLayouts {
+			value, err = time.Parse(layout, fmt.Sprintf("%s", src))
+			if err == nil {
+				break
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("invalid time %q: %w", src, err)
+		}
+	default:
+		return fmt.Errorf("unsupported time %T", src)
+	}
+	value = value.UTC()
+	switch field := any(c.field).(type) {
+	case *time.Time:
+		*field = value
+	case **time.Time:
+		*field = &value
+	}
+	return nil
+}
+
+// jsonColumn stores a field in JSON
+type jsonColumn struct {
+	field any
+}
+
+func (c jsonColumn) Value() (driver.Value, error) {
+	value, err := json.Marshal(c.field)
+	return string(value), err
+}
+
+func (c jsonColumn) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+	case string:
+		return json.Unmarshal([]byte(src), c.field)
+	case []byte:
+		return json.Unmarshal(src, c.field)
+	}
+	return fmt.Errorf("unsupported JSON %T", src)
+}
+`))
+
+// sqlRepositoryTests run the repository tests against SQLite, migrated with the project's migrations
+var sqlRepositoryTests = template.Must(template.New("sqlRepositoryTest").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	_ "modernc.org/sqlite"
+
+	"{{.Module}}/migrations"
+)
+
+// TestSQLRepository runs the repository tests against an in-memory SQLite database migrated to the latest version
+func TestSQLRepository(t *testing.T) {
+	testRepository(t, func(t *testing.T) Repository {
+		db, err := sql.Open("sqlite", ":memory:")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// every connection opens its own in-memory database
+		db.SetMaxOpenConns(1)
+		t.Cleanup(func() {
+			db.Close()
+		})
+		err = migrations.Up(context.Background(), db)
+		if err != nil {
+			t.Fatalf("failed to migrate: %v", err)
+		}
+		return NewSQLRepository(db)
+	})
+}
+`))
+
+// addRepositoryCmd represents the add repository command
+var addRepositoryCmd = &cobra.Command{
+	Use:   "repository [entity]",
+	Short: "Add a SQL implementation of an entity's repository",
+	Long: `Add a database/sql implementation of the repository of an entity generated by add entity, the
+migrations creating its table and tests running the repository tests against SQLite. The first
+repository adds the migrations package with the migrate entrypoint applying them, and the database
+category with the DSN of the --driver database. add field then adds columns with new migrations.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		driverName := repositoryDriver
+		if !cmd.Flags().Changed("driver") {
+			// the repositories after the first one use the project's database
+			manifest, err := readManifest()
+			if err != nil {
+				return err
+			}
+			if manifest.Database != "" {
+				driverName = manifest.Database
+			}
+		}
+		return addRepository(args[0], driverName)
+	},
+}
+
+func addRepository(entity string, driverName string) error {
+	driver, ok := sqlDrivers[driverName]
+	if !ok {
+		return fmt.Errorf("unsupported driver %q, expected one of: %s", driverName, strings.Join(sqlDriverNames(), ", "))
+	}
+	directory := entityDirectory(entity)
+	if _, err := os.Stat(filepath.Join(directory, "entity.go")); err != nil {
+		return fmt.Errorf("the entity %s doesn't exist", entity)
+	}
+	if _, err := os.Stat(filepath.Join(directory, sqlRepositoryFile)); err == nil {
+		return fmt.Errorf("the entity %s has a SQL repository already", entity)
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	if manifest.Database != "" && manifest.Database != driverName {
+		return fmt.Errorf("the repositories of the project use %s, not %s", manifest.Database, driverName)
+	}
+	module, err := projectModulePath()
+	if err != nil {
+		return err
+	}
+	err = requireModules(driver.module, sqliteModule)
+	if err != nil {
+		return err
+	}
+	err = initializeDatabase(driverName, module)
+	if err != nil {
+		return err
+	}
+	err = initializeMigrations(driverName, module, manifest.ProjectType != projectTypeLibrary)
+	if err != nil {
+		return err
+	}
+	columns, err := entityColumns(entity, driver)
+	if err != nil {
+		return err
+	}
+	err = writeTableMigrations(entity, driver, columns)
+	if err != nil {
+		return err
+	}
+	err = writeSQLRepository(entity, driverName, columns)
+	if err != nil {
+		return err
+	}
+	err = writeTemplate(filepath.Join(directory, sqlRepositoryTest), sqlRepositoryTests, map[string]string{"Package": entityPackageName(entity), "Module": module})
+	if err != nil {
+		return err
+	}
+	manifest.Database = driverName
+	return writeManifest(manifest)
+}
+
+// initializeDatabase adds the database category and package of the database component, configured for the
+// driver, unless the project has them. A database category without DSN gets it.
+func initializeDatabase(driverName string, module string) error {
+	component := projectComponents[databaseCategory]
+	exists, err := categoryExists(databaseCategory)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = createCategoryWithOptions(databaseCategory, categoryOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	for _, property := range component.properties {
+		exists, err := propertyExistsInCategory(databaseCategory, property.Section, property.Name)
+		if err != nil {
+			return err
+		}
+		if exists {
+			continue
+		}
+		switch property.Name {
+		case "driver":
+			property.Default = driverName
+		case "dsn":
+			property.Default = fmt.Sprintf(sqlDrivers[driverName].dsn, moduleDirectoryName(module))
+		}
+		err = property.add(databaseCategory)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(component.path); err == nil {
+		return nil
+	}
+	return writeComponentSource(component, module)
+}
+
+// entityColumns returns the columns of the fields of the entity's struct, the ID excluded
+func entityColumns(entity string, driver sqlDriver) ([]sqlColumn, error) {
+	path := filepath.Join(entityDirectory(entity), "entity.go")
+	source, err := parseGoSource(path)
+	if err != nil {
+		return nil, err
+	}
+	structType := findStruct(source.file, entityTypeName(entity))
+	if structType == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", entityTypeName(entity), path)
+	}
+	var columns []sqlColumn
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name == "ID" || !ast.IsExported(name.Name) {
+				continue
+			}
+			columns = append(columns, newSQLColumn(driver, name.Name, types.ExprString(field.Type)))
+		}
+	}
+	return columns, nil
+}
+
+// updateSQLRepository regenerates the SQL repository of the entity, if it has one, with the new field and
+// writes the migration adding its column
+func updateSQLRepository(entity string, field string, typeName string) error {
+	if _, err := os.Stat(filepath.Join(entityDirectory(entity), sqlRepositoryFile)); os.IsNotExist(err) {
+		return nil
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	driver, ok := sqlDrivers[manifest.Database]
+	if !ok {
+		return fmt.Errorf("unsupported driver %q of the project's repositories", manifest.Database)
+	}
+	columns, err := entityColumns(entity, driver)
+	if err != nil {
+		return err
+	}
+	err = writeColumnMigration(entity, driver, newSQLColumn(driver, field, typeName))
+	if err != nil {
+		return err
+	}
+	return writeSQLRepository(entity, manifest.Database, columns)
+}
+
+// writeSQLRepository writes the SQL repository of the entity with the queries of its columns
+func writeSQLRepository(entity string, driverName string, columns []sqlColumn) error {
+	driver := sqlDrivers[driverName]
+	table := driver.quoteIdentifier(tableName(entityTypeName(entity)))
+	id := driver.quoteIdentifier("id")
+	names := []string{id}
+	placeholders := []string{driver.placeholder(1)}
+	var assignments []string
+	for i, column := range columns {
+		names = append(names, driver.quoteIdentifier(column.Name))
+		placeholders = append(placeholders, driver.placeholder(i+2))
+		assignments = append(assignments, fmt.Sprintf("%s = %s", driver.quoteIdentifier(column.Name), driver.placeholder(i+1)))
+	}
+	selectQuery := fmt.Sprintf("SELECT %s FROM %s", strings.Join(names, ", "), table)
+	update := fmt.Sprintf("UPDATE %s SET %s WHERE %s = %s", table, strings.Join(assignments, ", "), id, driver.placeholder(len(columns)+1))
+	if len(columns) == 0 {
+		// an update of an entity without fields only checks it exists
+		update = fmt.Sprintf("UPDATE %s SET %s = %s WHERE %s = %s", table, id, id, id, driver.placeholder(1))
+	}
+	queries := map[string]string{
+		"Insert": fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(names, ", "), strings.Join(placeholders, ", ")),
+		"Select": selectQuery,
+		"Get":    fmt.Sprintf("%s WHERE %s = %s", selectQuery, id, driver.placeholder(1)),
+		"List":   fmt.Sprintf("%s ORDER BY %s", selectQuery, id),
+		"Update": update,
+		"Delete": fmt.Sprintf("DELETE FROM %s WHERE %s = %s", table, id, driver.placeholder(1)),
+		"Exists": fmt.Sprintf("SELECT 1 FROM %s WHERE %s = %s", table, id, driver.placeholder(1)),
+	}
+	data := map[string]any{
+		"Package": entityPackageName(entity),
+		"Type":    entityTypeName(entity),
+		"Table":   tableName(entityTypeName(entity)),
+		"Driver":  driverName,
+		"Columns": columns,
+	}
+	// the queries are raw strings unless they quote identifiers with backquotes
+	for name, query := range queries {
+		if strconv.CanBackquote(query) {
+			data[name] = "`" + query + "`"
+		} else {
+			data[name] = strconv.Quote(query)
+		}
+	}
+	return writeTemplate(filepath.Join(entityDirectory(entity), sqlRepositoryFile), sqlRepository, data)
+}
+
+// writeTemplate formats the code of the template and writes it to the path
+func writeTemplate(path string, source *template.Template, data any) error {
+	var code bytes.Buffer
+	err := source.Execute(&code, data)
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	return writeProjectFile(path, formattedCode)
+}
+
+func init() {
+	projectAddCmd.AddCommand(addRepositoryCmd)
+	addRepositoryCmd.Flags().StringVar(&repositoryDriver, "driver", defaultRepositoryDriver, fmt.Sprintf("Database the repository is generated for (%s), defaults to the project's database", strings.Join(sqlDriverNames(), ", ")))
+	addRepositoryCmd.RegisterFlagCompletionFunc("driver", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sqlDriverNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+}
@@ -0,0 +1,1090 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// resourcesDirectory holds a package per REST resource of the project's api
+const resourcesDirectory = "pkg/api"
+
+var resourceFields []string
+var resourceEntrypoint string
+
+// resourceContext describes the resource being generated
+type resourceContext struct {
+	Name    string
+	Package string
+	Type    string
+	// Path is the path of the resource's collection, /order-items for order_item
+	Path      string
+	Module    string
+	Framework string
+	// Entity is set when the resource serves the domain entity of the same name
+	Entity bool
+	Fields []resourceField
+}
+
+// resourceField is a field of the requests and responses of a resource
+type resourceField struct {
+	Name string
+	Type string
+	JSON string
+	// Sample is the literal of the field in the requests of the tests, empty for types without one
+	Sample string
+}
+
+// Required reports whether the requests must have a value in the field, which string fields must
+func (f resourceField) Required() bool {
+	return f.Type == "string"
+}
+
+// resourceFiles are the framework independent files of a resource's package by their name
+var resourceFiles = map[string]*template.Template{
+	"resource.go": template.Must(template.New("resource").Parse(`// Package {{.Package}} serves the {{.Path}} resource over HTTP. The handlers decode and validate the
+// requests and delegate to a Service.
+package {{.Package}}
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrNotFound is returned by the Service for IDs no resource has
+var ErrNotFound = errors.New("{{.Name}} not found")
+
+// Request is the body of the create and update requests
+type Request struct {
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.JSON}}"` + "`" + `
+{{- end}}
+}
+
+// Response is the body of the responses returning a resource
+type Response struct {
+	ID string ` + "`" + `json:"id"` + "`" + `
+{{- range .Fields}}
+	{{.Name}} {{.Type}} ` + "`" + `json:"{{.JSON}}"` + "`" + `
+{{- end}}
+}
+
+// ErrorResponse is the body of the failed requests, with the reason of each invalid field
+type ErrorResponse struct {
+	Error  string            ` + "`" + `json:"error"` + "`" + `
+	Fields map[string]string ` + "`" + `json:"fields,omitempty"` + "`" + `
+}
+
+// ValidationError is returned for requests with invalid fields, by their JSON name
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, 0, len(e.Fields))
+	for field, reason := range e.Fields {
+		reasons = append(reasons, fmt.Sprintf("%s %s", field, reason))
+	}
+	sort.Strings(reasons)
+	return "invalid request: " + strings.Join(reasons, ", ")
+}
+
+// Validate checks the request before it reaches the Service
+func (r Request) Validate() error {
+	invalid := map[string]string{}
+{{- range .Fields}}{{if .Required}}
+	if strings.TrimSpace(r.{{.Name}}) == "" {
+		invalid["{{.JSON}}"] = "is required"
+	}
+{{- end}}{{end}}
+	if len(invalid) > 0 {
+		return &ValidationError{Fields: invalid}
+	}
+	return nil
+}
+
+// Service implements the operations of the resource for the handlers
+type Service interface {
+	List(ctx context.Context) ([]Response, error)
+	Get(ctx context.Context, id string) (Response, error)
+	Create(ctx context.Context, request Request) (Response, error)
+	// Update replaces the resource with the ID
+	Update(ctx context.Context, id string, request Request) (Response, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// errorResponse returns the status and the body of the response to a failed request. Errors other than
+// ErrNotFound and ValidationError are internal, their message isn't returned.
+func errorResponse(err error) (int, ErrorResponse) {
+	var validation *ValidationError
+	switch {
+	case errors.As(err, &validation):
+		return http.StatusBadRequest, ErrorResponse{Error: "invalid request", Fields: validation.Fields}
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound, ErrorResponse{Error: err.Error()}
+	}
+	return http.StatusInternalServerError, ErrorResponse{Error: http.StatusText(http.StatusInternalServerError)}
+}
+
+// malformedResponse is the body of the response to a request whose body isn't the JSON of a Request
+var malformedResponse = ErrorResponse{Error: "malformed request body"}
+`)),
+	"handler_test.go": template.Must(template.New("handlerTest").Parse(`package {{.Package}}
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+{{if eq .Framework "gin"}}
+	"github.com/gin-gonic/gin"
+{{- else if eq .Framework "echo"}}
+	"github.com/labstack/echo/v4"
+{{- end}}
+{{- if .Entity}}
+
+	domain "{{.Module}}/pkg/domain/{{.Package}}"
+{{- end}}
+)
+
+// newTestHandler returns the router serving the resource with an empty Service
+func newTestHandler() http.Handler {
+{{- if eq .Framework "gin"}}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+{{- else if eq .Framework "echo"}}
+	router := echo.New()
+{{- else}}
+	router := http.NewServeMux()
+{{- end}}
+{{- if .Entity}}
+	NewHandler(NewRepositoryService(domain.NewMemoryRepository())).Register(router)
+{{- else}}
+	NewHandler(NewMemoryService()).Register(router)
+{{- end}}
+	return router
+}
+
+// sampleRequest returns a valid request with a value in its fields
+func sampleRequest() Request {
+	var request Request
+	return request
+}
+
+func TestHandler(t *testing.T) {
+	handler := newTestHandler()
+	body, err := json.Marshal(sampleRequest())
+	if err != nil {
+		t.Fatal(err)
+	}
+	created := serve[Response](t, handler, http.MethodPost, "{{.Path}}", string(body), http.StatusCreated)
+	if created.ID == "" {
+		t.Fatal("created resource has no ID")
+	}
+	if got := serve[Response](t, handler, http.MethodGet, "{{.Path}}/"+created.ID, "", http.StatusOK); !reflect.DeepEqual(got, created) {
+		t.Fatalf("get returned %+v, want %+v", got, created)
+	}
+	if got := serve[[]Response](t, handler, http.MethodGet, "{{.Path}}", "", http.StatusOK); len(got) != 1 || got[0].ID != created.ID {
+		t.Fatalf("list returned %+v, want the resource %s", got, created.ID)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"get missing", http.MethodGet, "{{.Path}}/missing", "", http.StatusNotFound},
+		{"create malformed", http.MethodPost, "{{.Path}}", "{", http.StatusBadRequest},
+{{- if .HasRequired}}
+		{"create invalid", http.MethodPost, "{{.Path}}", "{}", http.StatusBadRequest},
+		{"update invalid", http.MethodPut, "{{.Path}}/" + created.ID, "{}", http.StatusBadRequest},
+{{- end}}
+		{"update", http.MethodPut, "{{.Path}}/" + created.ID, string(body), http.StatusOK},
+		{"update missing", http.MethodPut, "{{.Path}}/missing", string(body), http.StatusNotFound},
+		{"delete", http.MethodDelete, "{{.Path}}/" + created.ID, "", http.StatusNoContent},
+		{"delete deleted", http.MethodDelete, "{{.Path}}/" + created.ID, "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serve[json.RawMessage](t, handler, tt.method, tt.path, tt.body, tt.status)
+		})
+	}
+}
+
+// serve sends the request to the handler, checks the status of the response and decodes its body
+func serve[T any](t *testing.T, handler http.Handler, method string, path string, body string, status int) T {
+	t.Helper()
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	if recorder.Code != status {
+		t.Fatalf("%s %s returned %d, want %d: %s", method, path, recorder.Code, status, recorder.Body)
+	}
+	var decoded T
+	if recorder.Body.Len() > 0 {
+		err := json.Unmarshal(recorder.Body.Bytes(), &decoded)
+		if err != nil {
+			t.Fatalf("%s %s returned an invalid body: %v", method, path, err)
+		}
+	}
+	return decoded
+}
+`)),
+}
+
+// resourceServices are the implementations of a resource's Service, by whether the resource serves an entity
+var resourceServices = map[bool]*template.Template{
+	false: template.Must(template.New("memoryService").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"sort"
+	"sync"
+)
+
+// MemoryService keeps the resources in memory, until the Service is backed by the domain of the project.
+// It's safe for concurrent use.
+type MemoryService struct {
+	mu        sync.RWMutex
+	resources map[string]Response
+}
+
+var _ Service = (*MemoryService)(nil)
+
+func NewMemoryService() *MemoryService {
+	return &MemoryService{resources: map[string]Response{}}
+}
+
+// List returns the resources sorted by ID
+func (s *MemoryService) List(ctx context.Context) ([]Response, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	responses := make([]Response, 0, len(s.resources))
+	for _, response := range s.resources {
+		responses = append(responses, response)
+	}
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ID < responses[j].ID
+	})
+	return responses, nil
+}
+
+func (s *MemoryService) Get(ctx context.Context, id string) (Response, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	response, ok := s.resources[id]
+	if !ok {
+		return Response{}, ErrNotFound
+	}
+	return response, nil
+}
+
+func (s *MemoryService) Create(ctx context.Context, request Request) (Response, error) {
+	id := make([]byte, 16)
+	_, err := rand.Read(id)
+	if err != nil {
+		return Response{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	response := newResponse(hex.EncodeToString(id), request)
+	s.resources[response.ID] = response
+	return response, nil
+}
+
+func (s *MemoryService) Update(ctx context.Context, id string, request Request) (Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[id]; !ok {
+		return Response{}, ErrNotFound
+	}
+	response := newResponse(id, request)
+	s.resources[id] = response
+	return response, nil
+}
+
+func (s *MemoryService) Delete(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.resources[id]; !ok {
+		return ErrNotFound
+	}
+	delete(s.resources, id)
+	return nil
+}
+
+func newResponse(id string, request Request) Response {
+	return Response{
+		ID: id,
+{{- range .Fields}}
+		{{.Name}}: request.{{.Name}},
+{{- end}}
+	}
+}
+`)),
+	true: template.Must(template.New("repositoryService").Parse(`package {{.Package}}
+
+import (
+	"context"
+	"errors"
+
+	domain "{{.Module}}/pkg/domain/{{.Package}}"
+)
+
+// RepositoryService implements the Service with the Repository of the {{.Type}} entity
+type RepositoryService struct {
+	repository domain.Repository
+}
+
+var _ Service = (*RepositoryService)(nil)
+
+func NewRepositoryService(repository domain.Repository) *RepositoryService {
+	return &RepositoryService{repository: repository}
+}
+
+func (s *RepositoryService) List(ctx context.Context) ([]Response, error) {
+	entities, err := s.repository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]Response, 0, len(entities))
+	for _, entity := range entities {
+		responses = append(responses, newResponse(entity))
+	}
+	return responses, nil
+}
+
+func (s *RepositoryService) Get(ctx context.Context, id string) (Response, error) {
+	entity, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Response{}, serviceError(err)
+	}
+	return newResponse(entity), nil
+}
+
+func (s *RepositoryService) Create(ctx context.Context, request Request) (Response, error) {
+	entity := &domain.{{.Type}}{}
+	request.apply(entity)
+	err := s.repository.Create(ctx, entity)
+	if err != nil {
+		return Response{}, err
+	}
+	return newResponse(entity), nil
+}
+
+// Update sets the fields of the request on the stored entity, the fields the resource doesn't expose keep their values
+func (s *RepositoryService) Update(ctx context.Context, id string, request Request) (Response, error) {
+	entity, err := s.repository.Get(ctx, id)
+	if err != nil {
+		return Response{}, serviceError(err)
+	}
+	request.apply(entity)
+	err = s.repository.Update(ctx, entity)
+	if err != nil {
+		return Response{}, serviceError(err)
+	}
+	return newResponse(entity), nil
+}
+
+func (s *RepositoryService) Delete(ctx context.Context, id string) error {
+	return serviceError(s.repository.Delete(ctx, id))
+}
+
+// serviceError turns the errors of the Repository into the errors of the Service
+func serviceError(err error) error {
+	if errors.Is(err, domain.ErrNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// apply sets the fields of the request on the entity
+func (r Request) apply(entity *domain.{{.Type}}) {
+{{- range .Fields}}
+	entity.{{.Name}} = r.{{.Name}}
+{{- end}}
+}
+
+func newResponse(entity *domain.{{.Type}}) Response {
+	return Response{
+		ID: entity.ID,
+{{- range .Fields}}
+		{{.Name}}: entity.{{.Name}},
+{{- end}}
+	}
+}
+`)),
+}
+
+// resourceHandlers are the handlers of a resource by HTTP framework, with how the api entrypoints of the
+// framework create their router
+var resourceHandlers = map[string]struct {
+	// routerConstructors are the functions main calls to create the router, package.Function
+	routerConstructors []string
+	handler            *template.Template
+}{
+	"gin": {routerConstructors: []string{"gin.Default", "gin.New"}, handler: template.Must(template.New("ginHandler").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler serves the {{.Path}} routes with the Service
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Register adds the routes of the resource to the router
+func (h *Handler) Register(router gin.IRouter) {
+	group := router.Group("{{.Path}}")
+	group.GET("", h.list)
+	group.GET("/:id", h.get)
+	group.POST("", h.create)
+	group.PUT("/:id", h.update)
+	group.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) list(c *gin.Context) {
+	responses, err := h.service.List(c.Request.Context())
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, responses)
+}
+
+func (h *Handler) get(c *gin.Context) {
+	response, err := h.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) create(c *gin.Context) {
+	request, ok := bindRequest(c)
+	if !ok {
+		return
+	}
+	response, err := h.service.Create(c.Request.Context(), request)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *Handler) update(c *gin.Context) {
+	request, ok := bindRequest(c)
+	if !ok {
+		return
+	}
+	response, err := h.service.Update(c.Request.Context(), c.Param("id"), request)
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) delete(c *gin.Context) {
+	err := h.service.Delete(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// bindRequest decodes and validates the body of the request, responding to the invalid ones
+func bindRequest(c *gin.Context) (Request, bool) {
+	var request Request
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, malformedResponse)
+		return request, false
+	}
+	err = request.Validate()
+	if err != nil {
+		fail(c, err)
+		return request, false
+	}
+	return request, true
+}
+
+func fail(c *gin.Context, err error) {
+	status, response := errorResponse(err)
+	c.JSON(status, response)
+}
+`))},
+	"echo": {routerConstructors: []string{"echo.New"}, handler: template.Must(template.New("echoHandler").Parse(`package {{.Package}}
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Handler serves the {{.Path}} routes with the Service
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Register adds the routes of the resource to the server
+func (h *Handler) Register(e *echo.Echo) {
+	group := e.Group("{{.Path}}")
+	group.GET("", h.list)
+	group.GET("/:id", h.get)
+	group.POST("", h.create)
+	group.PUT("/:id", h.update)
+	group.DELETE("/:id", h.delete)
+}
+
+func (h *Handler) list(c echo.Context) error {
+	responses, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(http.StatusOK, responses)
+}
+
+func (h *Handler) get(c echo.Context) error {
+	response, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) create(c echo.Context) error {
+	var request Request
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, malformedResponse)
+	}
+	if err := request.Validate(); err != nil {
+		return fail(c, err)
+	}
+	response, err := h.service.Create(c.Request().Context(), request)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(http.StatusCreated, response)
+}
+
+func (h *Handler) update(c echo.Context) error {
+	var request Request
+	if err := c.Bind(&request); err != nil {
+		return c.JSON(http.StatusBadRequest, malformedResponse)
+	}
+	if err := request.Validate(); err != nil {
+		return fail(c, err)
+	}
+	response, err := h.service.Update(c.Request().Context(), c.Param("id"), request)
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+func (h *Handler) delete(c echo.Context) error {
+	err := h.service.Delete(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return fail(c, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func fail(c echo.Context, err error) error {
+	status, response := errorResponse(err)
+	return c.JSON(status, response)
+}
+`))},
+	"std": {routerConstructors: []string{"http.NewServeMux"}, handler: template.Must(template.New("stdHandler").Parse(`package {{.Package}}
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+)
+
+// Handler serves the {{.Path}} routes with the Service
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+// Register adds the routes of the resource to the mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("GET {{.Path}}", h.list)
+	mux.HandleFunc("GET {{.Path}}/{id}", h.get)
+	mux.HandleFunc("POST {{.Path}}", h.create)
+	mux.HandleFunc("PUT {{.Path}}/{id}", h.update)
+	mux.HandleFunc("DELETE {{.Path}}/{id}", h.delete)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request) {
+	responses, err := h.service.List(r.Context())
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, responses)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	response, err := h.service.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	response, err := h.service.Create(r.Context(), request)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, response)
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	request, ok := decodeRequest(w, r)
+	if !ok {
+		return
+	}
+	response, err := h.service.Update(r.Context(), r.PathValue("id"), request)
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	err := h.service.Delete(r.Context(), r.PathValue("id"))
+	if err != nil {
+		fail(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRequest decodes and validates the body of the request, responding to the invalid ones
+func decodeRequest(w http.ResponseWriter, r *http.Request) (Request, bool) {
+	var request Request
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, malformedResponse)
+		return request, false
+	}
+	err = request.Validate()
+	if err != nil {
+		fail(w, err)
+		return request, false
+	}
+	return request, true
+}
+
+func fail(w http.ResponseWriter, err error) {
+	status, response := errorResponse(err)
+	writeJSON(w, status, response)
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	err := json.NewEncoder(w).Encode(body)
+	if err != nil {
+		log.Printf("failed to write response: %v", err)
+	}
+}
+`))},
+}
+
+// addResourceCmd represents the add resource command
+var addResourceCmd = &cobra.Command{
+	Use:   "resource [name]",
+	Short: "Add a REST resource to the api entrypoint",
+	Long: `Add a REST resource to pkg/api/<name>: the request and response bodies, their validation, a
+Service interface and the list, get, create, update and delete handlers delegating to it, written
+for the HTTP framework of the api entrypoint, with httptest tests. The routes are registered in
+the router of the entrypoint's main.go, under /<plural name>.
+The fields are given as --field name:type, string fields being required. A resource named after
+an entity of add entity serves it: its fields are the entity's and its Service uses the entity's
+Repository, main wiring the in-memory one. Without an entity, main wires a Service keeping the
+resources in memory.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		fields, err := parseEntityFields(resourceFields)
+		if err != nil {
+			return err
+		}
+		return addResource(args[0], fields, resourceEntrypoint)
+	},
+}
+
+// addResource generates the package of the resource and registers its routes in the api entrypoint
+func addResource(name string, fields [][2]string, entrypointName string) error {
+	err := checkEntityName("resource", name)
+	if err != nil {
+		return err
+	}
+	directory := filepath.Join(resourcesDirectory, entityPackageName(name))
+	if _, err := os.Stat(directory); err == nil {
+		return fmt.Errorf("resource %s already exists in %s", name, directory)
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	entrypoint, err := resourceEntrypointOf(manifest, entrypointName)
+	if err != nil {
+		return err
+	}
+	module, err := projectModulePath()
+	if err != nil {
+		return err
+	}
+	resource := resourceContext{
+		Name:      name,
+		Package:   entityPackageName(name),
+		Type:      entityTypeName(name),
+		Path:      "/" + strings.ReplaceAll(tableName(entityTypeName(name)), "_", "-"),
+		Module:    module,
+		Framework: entrypoint.Framework,
+	}
+	if _, err := os.Stat(filepath.Join(entityDirectory(name), "entity.go")); err == nil {
+		if len(fields) > 0 {
+			return fmt.Errorf("the resource %s serves the entity %s, its fields are the entity's, add them with add field", name, name)
+		}
+		resource.Entity = true
+		resource.Fields, err = entityResourceFields(name)
+	} else {
+		resource.Fields, err = newResourceFields(fields)
+	}
+	if err != nil {
+		return err
+	}
+	main, err := parseGoSource(entrypointContext{Name: entrypoint.Name}.mainPath())
+	if err != nil {
+		return err
+	}
+	err = registerResource(main, resource)
+	if err != nil {
+		return err
+	}
+	err = writeResourceFiles(directory, resource)
+	if err != nil {
+		return err
+	}
+	return main.write()
+}
+
+// resourceEntrypointOf returns the api entrypoint serving the resources: the one named, or else the
+// project's only one
+func resourceEntrypointOf(manifest *projectManifest, name string) (manifestEntrypoint, error) {
+	var entrypoints []manifestEntrypoint
+	var names []string
+	for _, entrypoint := range manifest.Entrypoints {
+		if entrypoint.Type != applicationTypeApi {
+			continue
+		}
+		if entrypoint.Name == name {
+			return entrypoint, nil
+		}
+		entrypoints = append(entrypoints, entrypoint)
+		names = append(names, entrypoint.Name)
+	}
+	switch {
+	case name != "":
+		return manifestEntrypoint{}, fmt.Errorf("the project has no api entrypoint %s", name)
+	case len(entrypoints) == 0:
+		return manifestEntrypoint{}, fmt.Errorf("the project has no api entrypoint, add one with add entrypoint -t %s", applicationTypeApi)
+	case len(entrypoints) > 1:
+		return manifestEntrypoint{}, fmt.Errorf("the project has several api entrypoints, choose one with --entrypoint (%s)", strings.Join(names, ", "))
+	}
+	return entrypoints[0], nil
+}
+
+// newResourceFields turns the name:type pairs of --field into the fields of the resource
+func newResourceFields(fields [][2]string) ([]resourceField, error) {
+	resourceFields := make([]resourceField, 0, len(fields))
+	seen := map[string]bool{}
+	for _, field := range fields {
+		name := entityTypeName(field[0])
+		if seen[name] {
+			return nil, fmt.Errorf("the field %s is given twice", name)
+		}
+		seen[name] = true
+		resourceFields = append(resourceFields, newResourceField(name, field[1]))
+	}
+	return resourceFields, nil
+}
+
+// entityResourceFields returns the exported fields of the entity but its ID
+func entityResourceFields(entity string) ([]resourceField, error) {
+	path := filepath.Join(entityDirectory(entity), "entity.go")
+	source, err := parseGoSource(path)
+	if err != nil {
+		return nil, err
+	}
+	structType := findStruct(source.file, entityTypeName(entity))
+	if structType == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", entityTypeName(entity), path)
+	}
+	var fields []resourceField
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if name.Name != "ID" && ast.IsExported(name.Name) {
+				fields = append(fields, newResourceField(name.Name, types.ExprString(field.Type)))
+			}
+		}
+	}
+	return fields, nil
+}
+
+func newResourceField(name string, typeName string) resourceField {
+	field := resourceField{Name: name, Type: typeName, JSON: columnName(name)}
+	field.Sample, _ = sampleLiteral(field.JSON, typeName)
+	return field
+}
+
+// HasRequired reports whether the requests of the resource have required fields
+func (r resourceContext) HasRequired() bool {
+	for _, field := range r.Fields {
+		if field.Required() {
+			return true
+		}
+	}
+	return false
+}
+
+// writeResourceFiles writes the package of the resource, then imports the packages of the fields' types and
+// sets the fields of the tests' sample request
+func writeResourceFiles(directory string, resource resourceContext) error {
+	files := map[string]*template.Template{
+		"handler.go": resourceHandlers[resource.Framework].handler,
+		"service.go": resourceServices[resource.Entity],
+	}
+	for name, source := range resourceFiles {
+		files[name] = source
+	}
+	for name, source := range files {
+		err := writeTemplate(filepath.Join(directory, name), source, resource)
+		if err != nil {
+			return err
+		}
+	}
+	definitions, err := parseGoSource(filepath.Join(directory, "resource.go"))
+	if err != nil {
+		return err
+	}
+	for _, field := range resource.Fields {
+		addTypeImports(definitions.fset, definitions.file, field.Type)
+	}
+	err = definitions.write()
+	if err != nil {
+		return err
+	}
+	tests, err := parseGoSource(filepath.Join(directory, "handler_test.go"))
+	if err != nil {
+		return err
+	}
+	var sample *ast.FuncDecl
+	for _, decl := range tests.file.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && function.Name.Name == "sampleRequest" {
+			sample = function
+		}
+	}
+	if sample == nil {
+		return fmt.Errorf("sampleRequest not found in %s", tests.path)
+	}
+	// the values are set right before the function returns the sample
+	statements := sample.Body.List
+	var assignments []ast.Stmt
+	for _, field := range resource.Fields {
+		if field.Sample == "" {
+			continue
+		}
+		assignments = append(assignments, &ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.SelectorExpr{X: ast.NewIdent("request"), Sel: ast.NewIdent(field.Name)}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{ast.NewIdent(field.Sample)},
+		})
+		addTypeImports(tests.fset, tests.file, field.Sample)
+	}
+	sample.Body.List = append(append(statements[:len(statements)-1:len(statements)-1], assignments...), statements[len(statements)-1])
+	return tests.write()
+}
+
+// registerResource makes the main function of the api entrypoint register the routes of the resource on its
+// router, after the router is created and the resources registered before
+func registerResource(main *configSource, resource resourceContext) error {
+	var conflict bool
+	ast.Inspect(main.file, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == resource.Package {
+			conflict = true
+		}
+		return !conflict
+	})
+	if conflict {
+		return fmt.Errorf("%s uses the name %s already, the package of the resource would shadow it", main.path, resource.Package)
+	}
+	var body *ast.BlockStmt
+	for _, decl := range main.file.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && function.Name.Name == "main" && function.Recv == nil {
+			body = function.Body
+		}
+	}
+	if body == nil {
+		return fmt.Errorf("function main not found in %s", main.path)
+	}
+	constructors := resourceHandlers[resource.Framework].routerConstructors
+	router, index := findRouter(body, constructors)
+	if router == "" {
+		return fmt.Errorf("%s doesn't create its router with %s, register the routes of the resource with its Handler", main.path, strings.Join(constructors, " or "))
+	}
+	for index+1 < len(body.List) && isRegistration(body.List[index+1]) {
+		index++
+	}
+
+	// the service of the resource is an in-memory one
+	service := &ast.CallExpr{Fun: &ast.SelectorExpr{X: ast.NewIdent(resource.Package), Sel: ast.NewIdent("NewMemoryService")}}
+	resourceImport := fmt.Sprintf("%s/%s/%s", resource.Module, resourcesDirectory, resource.Package)
+	astutil.AddImport(main.fset, main.file, resourceImport)
+	if resource.Entity {
+		domainName := resource.Package + "domain"
+		service = &ast.CallExpr{
+			Fun: &ast.SelectorExpr{X: ast.NewIdent(resource.Package), Sel: ast.NewIdent("NewRepositoryService")},
+			Args: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: ast.NewIdent(domainName), Sel: ast.NewIdent("NewMemoryRepository")},
+			}},
+		}
+		astutil.AddNamedImport(main.fset, main.file, domainName, fmt.Sprintf("%s/%s/%s", resource.Module, domainDirectory, resource.Package))
+	}
+	registration := &ast.ExprStmt{X: &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: ast.NewIdent(resource.Package), Sel: ast.NewIdent("NewHandler")},
+				Args: []ast.Expr{service},
+			},
+			Sel: ast.NewIdent("Register"),
+		},
+		Args: []ast.Expr{ast.NewIdent(router)},
+	}}
+	statements := append([]ast.Stmt{registration}, body.List[index+1:]...)
+	body.List = append(body.List[:index+1], statements...)
+	ast.SortImports(main.fset, main.file)
+	return nil
+}
+
+// findRouter returns the variable the statements assign the router created by one of the constructors to,
+// with the index of the statement
+func findRouter(body *ast.BlockStmt, constructors []string) (string, int) {
+	for i, statement := range body.List {
+		assignment, ok := statement.(*ast.AssignStmt)
+		if !ok || len(assignment.Lhs) != 1 || len(assignment.Rhs) != 1 {
+			continue
+		}
+		variable, ok := assignment.Lhs[0].(*ast.Ident)
+		if !ok {
+			continue
+		}
+		call, ok := assignment.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		for _, constructor := range constructors {
+			if types.ExprString(call.Fun) == constructor {
+				return variable.Name, i
+			}
+		}
+	}
+	return "", -1
+}
+
+// isRegistration reports whether the statement registers the routes of a resource, package.NewHandler(...).Register(router)
+func isRegistration(statement ast.Stmt) bool {
+	expression, ok := statement.(*ast.ExprStmt)
+	if !ok {
+		return false
+	}
+	call, ok := expression.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Register" {
+		return false
+	}
+	handler, ok := selector.X.(*ast.CallExpr)
+	if !ok {
+		return false
+	}
+	constructor, ok := handler.Fun.(*ast.SelectorExpr)
+	return ok && constructor.Sel.Name == "NewHandler"
+}
+
+// completeAPIEntrypoints completes --entrypoint with the api entrypoints of the project
+func completeAPIEntrypoints(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if err := enterProjectRoot(cmd, args); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, entrypoint := range manifest.Entrypoints {
+		if entrypoint.Type == applicationTypeApi && strings.HasPrefix(entrypoint.Name, toComplete) {
+			names = append(names, entrypoint.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	projectAddCmd.AddCommand(addResourceCmd)
+	addResourceCmd.Flags().StringArrayVarP(&resourceFields, "field", "f", nil, "Field of the resource as name:type, repeated for each field")
+	addResourceCmd.Flags().StringVar(&resourceEntrypoint, "entrypoint", "", "Api entrypoint serving the resource, defaults to the project's only one")
+	addResourceCmd.RegisterFlagCompletionFunc("entrypoint", completeAPIEntrypoints)
+}
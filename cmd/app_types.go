@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// applicationType generates the entrypoint of a kind of binary, cmd/<name>/main.go and whatever it needs
+type applicationType interface {
+	Name() string
+	Description() string
+	// Files returns the contents of the entrypoint's files by their path in the project
+	Files(entrypoint entrypointContext) (map[string]string, error)
+	// Dependencies lists the modules the files import
+	Dependencies(entrypoint entrypointContext) []string
+	// PostSteps adds what the entrypoint needs besides its files. They run once the project's config exists.
+	PostSteps(entrypoint entrypointContext) error
+}
+
+// entrypointContext describes the entrypoint being generated
+type entrypointContext struct {
+	Name   string
+	Module string
+	// Framework is the HTTP framework of api entrypoints
+	Framework string
+}
+
+func (e entrypointContext) mainPath() string {
+	return fmt.Sprintf("cmd/%s/main.go", e.Name)
+}
+
+// applicationTypes registers the application types init and add entrypoint generate
+var applicationTypes = map[string]applicationType{
+	applicationTypeApi:    apiApplication{},
+	applicationTypeCli:    cliApplication{},
+	applicationTypeWorker: workerApplication{},
+	applicationTypeCron:   cronApplication{},
+	applicationTypeGrpc:   grpcApplication{},
+}
+
+func resolveApplicationType(name string) (applicationType, error) {
+	application, ok := applicationTypes[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported application type %q, expected one of: %s", name, strings.Join(applicationTypeNames(), ", "))
+	}
+	return application, nil
+}
+
+func applicationTypeNames() []string {
+	names := make([]string, 0, len(applicationTypes))
+	for name := range applicationTypes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// completeApplicationTypes completes the flags taking an application type with the types and their descriptions
+func completeApplicationTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, 0, len(applicationTypes))
+	for _, name := range applicationTypeNames() {
+		if strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name+"\t"+applicationTypes[name].Description())
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProjectTypes completes init's type with the application types and library
+func completeProjectTypes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions, directive := completeApplicationTypes(cmd, args, toComplete)
+	if strings.HasPrefix(projectTypeLibrary, toComplete) {
+		completions = append(completions, projectTypeLibrary+"\tPackage without entrypoints")
+	}
+	return completions, directive
+}
+
+// finishEntrypoint runs the post steps of the entrypoint's type
+func finishEntrypoint(name string, appType string, framework string, module string) error {
+	application, err := resolveApplicationType(appType)
+	if err != nil {
+		return err
+	}
+	return application.PostSteps(entrypointContext{Name: name, Module: module, Framework: framework})
+}
+
+// apiEntrypoints are the main.go files of api entrypoints by HTTP framework, with the module the framework needs
+var apiEntrypoints = map[string]struct {
+	module   string
+	contents string
+}{
+	"gin": {module: "github.com/gin-gonic/gin", contents: `package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	message := fmt.Sprintf("Hello world!")
+	r := gin.Default()
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"hello": message,
+		})
+	})
+	r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
+}`},
+	"echo": {module: "github.com/labstack/echo/v4", contents: `package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+func main() {
+	message := fmt.Sprintf("Hello world!")
+	e := echo.New()
+	e.GET("/", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{
+			"hello": message,
+		})
+	})
+	e.Logger.Fatal(e.Start(":8080"))
+}`},
+	"std": {contents: `package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+func main() {
+	message := fmt.Sprintf("Hello world!")
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]string{
+			"hello": message,
+		})
+	})
+	log.Fatal(http.ListenAndServe(":8080", mux))
+}`},
+}
+
+const cliEntrypoint = `package main
+
+import (
+	"fmt"
+)
+
+func main() {
+	message := fmt.Sprintf("Hello world!")
+	fmt.Println(message)
+}`
+
+const cronModule = "github.com/robfig/cron/v3"
+
+// cronEntrypoint runs a job on a schedule until it's interrupted
+const cronEntrypoint = `package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/robfig/cron/v3"
+)
+
+// schedule is when the job runs, in the cron format or as @every <duration>
+const schedule = "@every 1m"
+
+func main() {
+	message := fmt.Sprintf("Hello world!")
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	scheduler := cron.New()
+	if _, err := scheduler.AddFunc(schedule, func() { run(ctx) }); err != nil {
+		log.Fatalf("invalid schedule %q: %v", schedule, err)
+	}
+	log.Println(message)
+	scheduler.Start()
+	<-ctx.Done()
+	<-scheduler.Stop().Done()
+}
+
+// run is the scheduled job. A run still going when the process is interrupted is waited for.
+func run(ctx context.Context) {
+	log.Println("job ran")
+}`
+
+// apiApplication serves HTTP with the framework of the entrypoint
+type apiApplication struct{}
+
+func (apiApplication) Name() string        { return applicationTypeApi }
+func (apiApplication) Description() string { return "HTTP server built with gin, echo or net/http" }
+
+func (apiApplication) Files(entrypoint entrypointContext) (map[string]string, error) {
+	api, ok := apiEntrypoints[entrypoint.Framework]
+	if !ok {
+		return nil, fmt.Errorf("unsupported HTTP framework %q, expected gin, echo or std", entrypoint.Framework)
+	}
+	return map[string]string{entrypoint.mainPath(): api.contents}, nil
+}
+
+func (apiApplication) Dependencies(entrypoint entrypointContext) []string {
+	if module := apiEntrypoints[entrypoint.Framework].module; module != "" {
+		return []string{module}
+	}
+	return nil
+}
+
+func (apiApplication) PostSteps(entrypointContext) error { return nil }
+
+// cliApplication is a command run to completion
+type cliApplication struct{}
+
+func (cliApplication) Name() string        { return applicationTypeCli }
+func (cliApplication) Description() string { return "Command-line program run to completion" }
+
+func (cliApplication) Files(entrypoint entrypointContext) (map[string]string, error) {
+	return map[string]string{entrypoint.mainPath(): cliEntrypoint}, nil
+}
+
+func (cliApplication) Dependencies(entrypointContext) []string { return nil }
+func (cliApplication) PostSteps(entrypointContext) error       { return nil }
+
+// cronApplication runs a job on a schedule
+type cronApplication struct{}
+
+func (cronApplication) Name() string        { return applicationTypeCron }
+func (cronApplication) Description() string { return "Process running a job on a cron schedule" }
+
+func (cronApplication) Files(entrypoint entrypointContext) (map[string]string, error) {
+	return map[string]string{entrypoint.mainPath(): cronEntrypoint}, nil
+}
+
+func (cronApplication) Dependencies(entrypointContext) []string { return []string{cronModule} }
+func (cronApplication) PostSteps(entrypointContext) error       { return nil }
+
+// workerApplication runs jobs with the worker package the worker entrypoints share
+type workerApplication struct{}
+
+func (workerApplication) Name() string { return applicationTypeWorker }
+func (workerApplication) Description() string {
+	return "Long-running process consuming and scheduling jobs, with a health endpoint"
+}
+
+func (workerApplication) Files(entrypoint entrypointContext) (map[string]string, error) {
+	var code bytes.Buffer
+	err := workerEntrypoint.Execute(&code, map[string]string{"Module": entrypoint.Module})
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{entrypoint.mainPath(): code.String()}, nil
+}
+
+func (workerApplication) Dependencies(entrypointContext) []string { return nil }
+
+func (workerApplication) PostSteps(entrypoint entrypointContext) error {
+	return initializeWorker(entrypoint.Module)
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+)
+
+// projectComponent is a piece of infrastructure init can add to the project: a package and the config category it reads
+type projectComponent struct {
+	category   string
+	properties []propertySpec
+	path       string
+	source     *template.Template
+}
+
+var projectComponents = map[string]projectComponent{
+	"database": {
+		category: "database",
+		properties: []propertySpec{
+			{Name: "driver", Default: "postgres", Description: "Name of the registered database/sql driver"},
+			{Name: "dsn", Required: true, Secret: true, Description: "Data source name passed to the driver"},
+			{Name: "maxOpenConns", Type: "int", Default: "10", Description: "Maximum number of open connections"},
+		},
+		path: "pkg/infra/database/database.go",
+		source: template.Must(template.New("database").Parse(`package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"{{.Module}}/pkg/infra/config"
+)
+
+// Open opens the database configured by the database category. The configured driver has to be
+// registered, usually by importing it for side effects in the entrypoint.
+func Open(cfg *config.DatabaseConfig) (*sql.DB, error) {
+	db, err := sql.Open(cfg.Driver, cfg.Dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	return db, nil
+}
+`)),
+	},
+	"logging": {
+		category: "logging",
+		properties: []propertySpec{
+			{Name: "level", Default: "info", Description: "Minimal level of logged records: debug, info, warn or error"},
+			{Name: "format", Default: "text", Description: "Format of the records: text or json"},
+		},
+		path: "pkg/infra/logging/logging.go",
+		source: template.Must(template.New("logging").Parse(`package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"{{.Module}}/pkg/infra/config"
+)
+
+// New returns a logger writing the records to stderr at the configured level and in the configured format
+func New(cfg *config.LoggingConfig) (*slog.Logger, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(cfg.Level))
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level %q: %w", cfg.Level, err)
+	}
+	options := &slog.HandlerOptions{Level: level}
+	switch cfg.Format {
+	case "json":
+		return slog.New(slog.NewJSONHandler(os.Stderr, options)), nil
+	case "text":
+		return slog.New(slog.NewTextHandler(os.Stderr, options)), nil
+	}
+	return nil, fmt.Errorf("invalid log format %q", cfg.Format)
+}
+`)),
+	},
+	"metrics": {
+		category: "metrics",
+		properties: []propertySpec{
+			{Name: "enabled", Type: "bool", Default: "true", Description: "Whether the metrics are served"},
+			{Name: "address", Default: ":9090", Description: "Address the metrics server listens on"},
+			{Name: "path", Default: "/metrics", Description: "Path the metrics are served at"},
+		},
+		path: "pkg/infra/metrics/metrics.go",
+		source: template.Must(template.New("metrics").Parse(`package metrics
+
+import (
+	"expvar"
+	"net/http"
+
+	"{{.Module}}/pkg/infra/config"
+)
+
+// Serve exposes the expvar variables at the configured address and path, until the server fails.
+// It returns right away when the metrics are disabled.
+func Serve(cfg *config.MetricsConfig) error {
+	if !cfg.Enabled {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle(cfg.Path, expvar.Handler())
+	return http.ListenAndServe(cfg.Address, mux)
+}
+`)),
+	},
+}
+
+func projectComponentNames() []string {
+	names := make([]string, 0, len(projectComponents))
+	for name := range projectComponents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// initializeComponent creates the component's category and writes its package
+func initializeComponent(name string, module string) error {
+	component := projectComponents[name]
+	err := createCategoryWithOptions(component.category, categoryOptions{})
+	if err != nil {
+		return err
+	}
+	for _, property := range component.properties {
+		err = property.add(component.category)
+		if err != nil {
+			return err
+		}
+	}
+	return writeComponentSource(component, module)
+}
+
+// writeComponentSource writes the component's package
+func writeComponentSource(component projectComponent, module string) error {
+	var code bytes.Buffer
+	err := component.source.Execute(&code, map[string]string{"Module": module})
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(component.path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(component.path, formattedCode, 0644)
+}
@@ -19,6 +19,7 @@ and usage of using your command. For example:
 Cobra is a CLI library for Go that empowers applications.
 This application is a tool to generate the needed files
 to quickly create a Cobra application.`,
+	PersistentPreRunE: enterProjectRoot,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("config called")
 	},
@@ -4,223 +4,269 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
-	"bytes"
 	"fmt"
 	"github.com/spf13/cobra"
 	"go/ast"
-	"go/format"
 	"go/parser"
-	"go/printer"
 	"go/token"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
-	"io/fs"
+	"gopkg.in/yaml.v3"
 	"os"
 	"strings"
 )
 
 var propertyType string
+var propertySection string
+var propertyOpts propertyOptions
+var propertyWithFlag bool
+
+// propertyOptions hold the optional markers of a property, stored as struct tags of its field
+type propertyOptions struct {
+	Default     string
+	Required    bool
+	Secret      bool
+	Description string
+	// Flag is the name of the flag overriding the property, empty without a flag
+	Flag string
+}
+
+func (o propertyOptions) tag(formatTag string) string {
+	tags := []string{formatTag}
+	if o.Default != "" {
+		tags = append(tags, fmt.Sprintf("default:%q", o.Default))
+	}
+	if o.Required {
+		tags = append(tags, `validate:"required"`)
+	}
+	if o.Secret {
+		tags = append(tags, `secret:"true"`)
+	}
+	if o.Flag != "" {
+		tags = append(tags, fmt.Sprintf("flag:%q", o.Flag))
+	}
+	return "`" + strings.Join(tags, " ") + "`"
+}
+
+// value returns the value written to the config file for a new property
+func (o propertyOptions) value(typeName string) (any, error) {
+	if o.Default == "" {
+		return resolveDefaultValueForType(typeName), nil
+	}
+	var value any
+	err := yaml.Unmarshal([]byte(o.Default), &value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse default value %q: %w", o.Default, err)
+	}
+	return value, nil
+}
 
 // addCmd represents the add command
 var addCmd = &cobra.Command{
 	Use:   "add [category_name] [property_name]",
 	Short: "Add a new property to already existing command set",
+	Long: `Add a new property to already existing command set. The property gets a default value
+in the category's config file and a field in the category's struct. With --section the property
+is nested under the given key, both in the file and in the struct. With --flag, or in categories
+created with --flags, the property can be overridden by a command-line flag named after its keys.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		if len(args) != 2 {
 			return fmt.Errorf("unexpected number of arguments")
 		}
 		category := strings.ToLower(args[0])
 		propertyName := strings.ToLower(args[1])
-		if exists, err := categoryExists(category); !exists || err != nil {
-			if err != nil {
-				return err
-			}
-			return fmt.Errorf("the category %v doesn't exist", category)
+		section := strings.ToLower(propertySection)
+		return addProperty(category, section, propertyName, propertyType, propertyOpts, propertyWithFlag)
+	},
+}
+
+// addProperty adds the property after checking the category exists and doesn't have it yet. Properties of
+// categories created with flags get a flag even without withFlag.
+func addProperty(category string, section string, propertyName string, typeName string, options propertyOptions, withFlag bool) error {
+	err := checkConfigName("property", propertyName)
+	if err != nil {
+		return err
+	}
+	if section != "" {
+		err = checkConfigName("section", section)
+		if err != nil {
+			return err
 		}
-		if exists, err := propertyExistsInCategory(category, propertyName); exists || err != nil {
-			if err != nil {
-				return err
-			}
-			return fmt.Errorf("the property %v in category %v exists already", propertyName, category)
+	}
+	if exists, err := categoryExists(category); !exists || err != nil {
+		if err != nil {
+			return err
 		}
-		err := createPropertyOnCategory(category, propertyName, propertyType)
+		return fmt.Errorf("the category %v doesn't exist", category)
+	}
+	if exists, err := propertyExistsInCategory(category, section, propertyName); exists || err != nil {
 		if err != nil {
 			return err
 		}
-		return nil
-	},
+		return fmt.Errorf("the property %v in category %v exists already", propertyName, category)
+	}
+	flagged, err := categoryHasFlags(category)
+	if err != nil {
+		return err
+	}
+	if withFlag || flagged {
+		err = checkFlagType(typeName)
+		if err != nil {
+			return err
+		}
+		options.Flag = flagName(category, propertyPath(section, propertyName))
+	}
+	return createPropertyOnCategory(category, section, propertyName, typeName, options)
+}
+
+// checkConfigName fails unless the name of the category, section or property makes a Go identifier once it
+// is the name of a struct field
+func checkConfigName(kind string, name string) error {
+	if !token.IsIdentifier(fieldName(name)) {
+		return fmt.Errorf("invalid %s name %q, use letters, digits and _, starting with a letter", kind, name)
+	}
+	return nil
 }
 
 // todo can signature be simpler? Without error that is
 func categoryExists(category string) (bool, error) {
-	configDir := "config"
-	fileSuffix := "yaml"
-	filePath := fmt.Sprintf("%s/%s.%s", configDir, category, fileSuffix)
-	_, err := os.Stat(filePath)
-	if os.IsNotExist(err) {
+	if _, found := findCategoryFormat(category); !found {
 		return false, nil
 	}
 
-	configSourceFilesDir := "pkg/infra/config"
-	configSourceFileSuffix := "go"
-	configSourcePath := fmt.Sprintf("%s/%s.%s", configSourceFilesDir, category, configSourceFileSuffix)
-	_, err = os.Stat(configSourcePath)
+	sourcePath := configSourcePath(category)
+	_, err := os.Stat(sourcePath)
 	if os.IsNotExist(err) {
 		return false, nil
 	}
 
-	var configStructureFound bool
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, configSourcePath, nil, parser.AllErrors|parser.ParseComments)
+	source, err := parseConfigSource(category)
 	if err != nil {
 		return false, err
 	}
-	for _, decl := range node.Decls {
-		declaration, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
-		if declaration.Tok != token.TYPE {
-			continue
-		}
-		typeSpec, ok := declaration.Specs[0].(*ast.TypeSpec)
-		if !ok {
-			continue
-		}
-		if typeSpec.Name.Name == fmt.Sprintf("%sConfig", cases.Title(language.Und).String(category)) {
-			configStructureFound = true
-			break
-		}
-	}
-	return configStructureFound, nil
+	return source.categoryStruct(category) != nil, nil
 }
 
-func propertyExistsInCategory(category string, name string) (bool, error) {
-	// FIXME duplicated
-	configSourceFilesDir := "pkg/infra/config"
-	configSourceFileSuffix := "go"
-	configSourcePath := fmt.Sprintf("%s/%s.%s", configSourceFilesDir, category, configSourceFileSuffix)
-
-	// FIXME duplicated localizing the struct
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, configSourcePath, nil, parser.AllErrors|parser.ParseComments)
+func propertyExistsInCategory(category string, section string, name string) (bool, error) {
+	source, err := parseConfigSource(category)
 	if err != nil {
 		return false, err
 	}
-	for _, decl := range node.Decls {
-		declaration, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
-		if declaration.Tok != token.TYPE {
-			continue
-		}
-		typeSpec, ok := declaration.Specs[0].(*ast.TypeSpec)
-		if !ok {
-			continue
-		}
-		if typeSpec.Name.Name != fmt.Sprintf("%sConfig", cases.Title(language.Und).String(category)) {
-			continue
-		}
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			continue
-		}
-		for _, prop := range structType.Fields.List {
-			for _, propName := range prop.Names {
-				if strings.ToLower(propName.Name) == name {
-					return true, nil
-				}
-			}
-		}
+	structType, err := source.propertyStruct(category, section, "", false)
+	if err != nil || structType == nil {
+		return false, err
 	}
-	return false, nil
+	return findField(structType, name) != nil, nil
 }
 
-// fixme every name is title case, even if the user's input is not. The rest of the letters are lower case
-// fixme if I give type like time.Duration, there should be an import added. Let's do it for time for now
-func createPropertyOnCategory(category string, name string, typeName string) error {
-	// fixme duplicated path resolving
-	configDir := "config"
-	fileSuffix := "yaml"
-	filePath := fmt.Sprintf("%s/%s.%s", configDir, category, fileSuffix)
-	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, fs.ModePerm)
+func createPropertyOnCategory(category string, section string, name string, typeName string, options propertyOptions) error {
+	err := checkTypeName(typeName)
 	if err != nil {
 		return err
 	}
-	_, err = file.WriteString(fmt.Sprintf("%s: %v\n", name, resolveDefaultValueForType(typeName)))
+	fileFormat, found := findCategoryFormat(category)
+	if !found {
+		return fmt.Errorf("config file of category %s not found", category)
+	}
+	backend, err := projectConfigBackend()
 	if err != nil {
 		return err
 	}
-	err = file.Close()
+	err = checkFormatType(backend, fileFormat, typeName)
+	if err != nil {
+		return err
+	}
+	value, err := options.value(typeName)
 	if err != nil {
 		return err
 	}
 
-	// FIXME duplicated resolving source file path
-	configSourceFilesDir := "pkg/infra/config"
-	configSourceFileSuffix := "go"
-	configSourcePath := fmt.Sprintf("%s/%s.%s", configSourceFilesDir, category, configSourceFileSuffix)
-
-	// FIXME duplicated localizing the struct
-	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, configSourcePath, nil, parser.AllErrors|parser.ParseComments)
+	// the source is edited before the file, the file keeping no key of a property the source refused
+	source, err := parseConfigSource(category)
 	if err != nil {
 		return err
 	}
-	for _, decl := range node.Decls {
-		declaration, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
-		if declaration.Tok != token.TYPE {
-			continue
-		}
-		typeSpec, ok := declaration.Specs[0].(*ast.TypeSpec)
-		if !ok {
-			continue
-		}
-		if typeSpec.Name.Name != fmt.Sprintf("%sConfig", cases.Title(language.Und).String(category)) {
-			continue
-		}
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			continue
-		}
-		structType.Fields.List = append(structType.Fields.List, &ast.Field{
-			Names: []*ast.Ident{
-				{
-					Name: cases.Title(language.Und).String(name),
-				},
+	original, err := os.ReadFile(source.path)
+	if err != nil {
+		return err
+	}
+	structType, err := source.propertyStruct(category, section, propertyOptions{}.tag(backend.StructTag(fileFormat, section)), true)
+	if err != nil {
+		return err
+	}
+	field := &ast.Field{
+		Names: []*ast.Ident{
+			{
+				Name: fieldName(name),
 			},
-			Type: ast.NewIdent(typeName),
-			Tag:  &ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("`yaml:\"%s\"`", name)},
-		})
-		break
+		},
+		Type: ast.NewIdent(typeName),
+		Tag:  &ast.BasicLit{Kind: token.STRING, Value: options.tag(backend.StructTag(fileFormat, name))},
 	}
-	var buf bytes.Buffer
-	err = printer.Fprint(&buf, fset, node)
+	structType.Fields.List = append(structType.Fields.List, field)
+	addTypeImports(source.fset, source.file, typeName)
+	if options.Description != "" {
+		source.setFieldDoc(category, section, name, options.Description)
+	}
+	err = source.write()
 	if err != nil {
 		return err
 	}
-	code := buf.String()
-	formattedCode, err := format.Source([]byte(code))
+	err = fileFormat.SetProperty(configFilePath(category, fileFormat), propertyPath(section, name), value)
 	if err != nil {
+		if restoreErr := os.WriteFile(source.path, original, 0644); restoreErr != nil {
+			return fmt.Errorf("%w, and failed to restore %s: %v", err, source.path, restoreErr)
+		}
 		return err
 	}
-	err = os.WriteFile(configSourcePath, formattedCode, 0644)
+	if options.Flag != "" {
+		err = generateCategoryFlags(category)
+		if err != nil {
+			return err
+		}
+	}
+	return generateCategoryTests(category)
+}
+
+// checkFormatType fails for types the config library can't decode from the category's file, the values of
+// dotenv files being strings only the env library makes maps of
+func checkFormatType(backend configBackend, fileFormat configFormat, typeName string) error {
+	expr, err := parser.ParseExpr(typeName)
 	if err != nil {
 		return err
 	}
+	if kind, _ := classifyType(expr); kind == mapValue && fileFormat.Name() == "dotenv" && backend.Name() != "env" {
+		return fmt.Errorf("the %s config library can't read maps from dotenv files, use another format for the category", backend.Name())
+	}
 	return nil
 }
 
+func propertyPath(section string, name string) []string {
+	if section == "" {
+		return []string{name}
+	}
+	return []string{section, name}
+}
+
+// resolveDefaultValueForType returns the zero value written for a property without a default, slices and
+// maps getting an empty one the config libraries can decode
 func resolveDefaultValueForType(name string) any {
-	if strings.Contains(name, "int") {
-		return 0
+	expr, err := parser.ParseExpr(name)
+	if err != nil {
+		return "string_value"
 	}
-	if strings.Contains(name, "float") {
+	kind, _ := classifyType(expr)
+	switch kind {
+	case integerValue:
+		return 0
+	case floatValue:
 		return 0.0
+	case boolValue:
+		return false
+	case durationValue:
+		return "0s"
+	case sliceValue:
+		return []any{}
+	case mapValue:
+		return map[string]any{}
 	}
 	return "string_value"
 }
@@ -228,4 +274,10 @@ func resolveDefaultValueForType(name string) any {
 func init() {
 	configCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVarP(&propertyType, "propertyType", "t", "string", "Provide type of the property")
+	addCmd.Flags().StringVarP(&propertySection, "section", "s", "", "Nest the property under the given section of the category")
+	addCmd.Flags().StringVar(&propertyOpts.Default, "default", "", "Default value of the property, written to the config file")
+	addCmd.Flags().BoolVar(&propertyOpts.Required, "required", false, "Mark the property as required")
+	addCmd.Flags().BoolVar(&propertyOpts.Secret, "secret", false, "Mark the property as holding a secret")
+	addCmd.Flags().BoolVar(&propertyWithFlag, "flag", false, "Generate a command-line flag overriding the property")
+	addCmd.Flags().StringVarP(&propertyOpts.Description, "description", "d", "", "Description of the property, stored as the doc comment of its field")
 }
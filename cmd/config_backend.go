@@ -0,0 +1,424 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"template/config"
+)
+
+// configBackend generates the code loading a category with a particular config library
+type configBackend interface {
+	Name() string
+	// Formats lists the config file formats the library can read, the first one is the default
+	Formats() []string
+	// Modules lists the modules needed by the generated code
+	Modules(fileFormat configFormat) []string
+	// Imports lists the packages imported by the constructor of a category
+	Imports(fileFormat configFormat) []string
+	// StructTag returns the tag of the field holding the property, without the backticks
+	StructTag(fileFormat configFormat, property string) string
+	// LoadStatements returns the beginning of the category's constructor, which declares err and fills config
+	LoadStatements(category string, fileFormat configFormat) []ast.Stmt
+	// Declarations returns the functions the constructor of a category calls, declared after it
+	Declarations(category string, fileFormat configFormat) []ast.Decl
+	// Helpers returns the files, by name, the generated code needs next to the categories
+	Helpers() map[string]string
+	// Environment tells whether the generated code overrides the properties with environment variables
+	Environment() bool
+}
+
+var configBackends = map[string]configBackend{
+	"viper": viperBackend{},
+	"koanf": koanfBackend{},
+	"yaml":  yamlBackend{},
+	"env":   envBackend{},
+}
+
+func resolveConfigBackend(name string) (configBackend, error) {
+	backend, ok := configBackends[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported config library %q, expected one of: %s", name, strings.Join(configBackendNames(), ", "))
+	}
+	return backend, nil
+}
+
+func configBackendNames() []string {
+	names := make([]string, 0, len(configBackends))
+	for name := range configBackends {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// projectConfigBackend returns the config library recorded in the manifest
+func projectConfigBackend() (configBackend, error) {
+	manifest, err := readManifest()
+	if err != nil {
+		return nil, err
+	}
+	return resolveConfigBackend(manifest.ConfigLibrary)
+}
+
+// checkBackendFormat fails unless the backend can read files of the format
+func checkBackendFormat(backend configBackend, fileFormat configFormat) error {
+	for _, name := range backend.Formats() {
+		if name == fileFormat.Name() {
+			return nil
+		}
+	}
+	return fmt.Errorf("config library %s can't read %s files, supported formats: %s", backend.Name(), fileFormat.Name(), strings.Join(backend.Formats(), ", "))
+}
+
+// ensureBackendHelpers writes the helper files of the backend which don't exist yet
+func ensureBackendHelpers(backend configBackend) error {
+	for name, contents := range backend.Helpers() {
+		path := fmt.Sprintf("%s/%s", config.ConfigSourceDirectory, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		err := os.WriteFile(path, []byte(contents), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type viperBackend struct{}
+
+func (viperBackend) Name() string { return "viper" }
+
+func (viperBackend) Formats() []string { return []string{"yaml", "json", "toml", "dotenv"} }
+
+func (viperBackend) Modules(configFormat) []string { return []string{config.ConfigLibraryName} }
+
+func (viperBackend) Imports(configFormat) []string {
+	return []string{"strings", config.ConfigLibraryName}
+}
+
+// StructTag uses the tag viper decodes with, keys of files it lowercases still match the tag regardless of the case
+func (viperBackend) StructTag(fileFormat configFormat, property string) string {
+	return fmt.Sprintf("mapstructure:%q", fileFormat.Key(property))
+}
+
+// LoadStatements reads the file with the viper instance of the category returned by new<Category>Viper
+func (viperBackend) LoadStatements(category string, fileFormat configFormat) []ast.Stmt {
+	instanceCall := func(method string, args ...ast.Expr) *ast.CallExpr {
+		return selectorCall("v", method, args...)
+	}
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("v"), ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(categoryViperName(category))}},
+		},
+		errorReturnStmt(),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{instanceCall("ReadInConfig")},
+		},
+		errorReturnStmt(),
+		newConfigStmt(category),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{instanceCall("Unmarshal", ast.NewIdent("config"))},
+		},
+		errorReturnStmt(),
+	}
+}
+
+// Declarations returns new<Category>Viper, setting up the viper instance of the category for its constructor
+// and its loader: CATEGORY_KEY environment variables override the file, and the flags of the category
+// override both once they are bound in it
+func (viperBackend) Declarations(category string, fileFormat configFormat) []ast.Decl {
+	instanceCall := func(method string, args ...ast.Expr) *ast.CallExpr {
+		return selectorCall("v", method, args...)
+	}
+	return []ast.Decl{&ast.FuncDecl{
+		Doc: &ast.CommentGroup{List: []*ast.Comment{
+			{Text: fmt.Sprintf("// %s returns the viper instance reading %s, overridden by the environment", categoryViperName(category), configFilePath(category, fileFormat))},
+		}},
+		Name: ast.NewIdent(categoryViperName(category)),
+		Type: &ast.FuncType{Results: &ast.FieldList{List: []*ast.Field{
+			{Type: &ast.StarExpr{X: &ast.SelectorExpr{X: ast.NewIdent("viper"), Sel: ast.NewIdent("Viper")}}},
+			{Type: ast.NewIdent("error")},
+		}}},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{ast.NewIdent("v")},
+				Tok: token.DEFINE,
+				Rhs: []ast.Expr{selectorCall("viper", "New")},
+			},
+			&ast.ExprStmt{X: instanceCall("SetConfigFile", stringLiteral(configFilePath(category, fileFormat)))},
+			&ast.ExprStmt{X: instanceCall("SetEnvPrefix", stringLiteral(category))},
+			&ast.ExprStmt{X: instanceCall("SetEnvKeyReplacer", selectorCall("strings", "NewReplacer", stringLiteral("."), stringLiteral("_")))},
+			&ast.ExprStmt{X: instanceCall("AutomaticEnv")},
+			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("v"), ast.NewIdent("nil")}},
+		}},
+	}}
+}
+
+func categoryViperName(category string) string {
+	return fmt.Sprintf("new%sViper", fieldName(category))
+}
+
+func (viperBackend) Helpers() map[string]string { return nil }
+
+type koanfBackend struct{}
+
+const koanfModule = "github.com/knadh/koanf/v2"
+
+// koanfParsers are the parser packages of koanf and their package names, by format
+var koanfParsers = map[string][2]string{
+	"yaml":   {"github.com/knadh/koanf/parsers/yaml", "yaml"},
+	"json":   {"github.com/knadh/koanf/parsers/json", "json"},
+	"toml":   {"github.com/knadh/koanf/parsers/toml/v2", "toml"},
+	"dotenv": {"github.com/knadh/koanf/parsers/dotenv", "dotenv"},
+}
+
+func (viperBackend) Environment() bool { return true }
+
+func (koanfBackend) Name() string { return "koanf" }
+
+func (koanfBackend) Formats() []string { return []string{"yaml", "json", "toml", "dotenv"} }
+
+func (b koanfBackend) Modules(fileFormat configFormat) []string {
+	return b.Imports(fileFormat)
+}
+
+func (koanfBackend) Imports(fileFormat configFormat) []string {
+	return []string{koanfModule, "github.com/knadh/koanf/providers/file", koanfParsers[fileFormat.Name()][0]}
+}
+
+// StructTag uses koanf's own tag, its keys are matched exactly
+func (koanfBackend) StructTag(fileFormat configFormat, property string) string {
+	return fmt.Sprintf("koanf:%q", fileFormat.Key(property))
+}
+
+func (koanfBackend) LoadStatements(category string, fileFormat configFormat) []ast.Stmt {
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("k")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{selectorCall("koanf", "New", stringLiteral("."))},
+		},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{X: ast.NewIdent("k"), Sel: ast.NewIdent("Load")},
+				Args: []ast.Expr{
+					selectorCall("file", "Provider", stringLiteral(configFilePath(category, fileFormat))),
+					selectorCall(koanfParsers[fileFormat.Name()][1], "Parser"),
+				},
+			}},
+		},
+		errorReturnStmt(),
+		newConfigStmt(category),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: ast.NewIdent("k"), Sel: ast.NewIdent("Unmarshal")},
+				Args: []ast.Expr{stringLiteral(""), ast.NewIdent("config")},
+			}},
+		},
+		errorReturnStmt(),
+	}
+}
+
+func (koanfBackend) Declarations(string, configFormat) []ast.Decl { return nil }
+
+func (koanfBackend) Helpers() map[string]string { return nil }
+
+// yamlBackend reads the files with nothing but the yaml package and the standard library
+type yamlBackend struct{}
+
+const yamlModule = "gopkg.in/yaml.v3"
+
+func (yamlBackend) Name() string { return "yaml" }
+
+func (yamlBackend) Formats() []string { return []string{"yaml"} }
+
+func (yamlBackend) Modules(configFormat) []string { return []string{yamlModule} }
+
+func (yamlBackend) Imports(configFormat) []string { return []string{"os", yamlModule} }
+
+func (yamlBackend) StructTag(fileFormat configFormat, property string) string {
+	return fileFormat.StructTag(property)
+}
+
+func (koanfBackend) Environment() bool { return false }
+
+func (yamlBackend) LoadStatements(category string, fileFormat configFormat) []ast.Stmt {
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("contents"), ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{selectorCall("os", "ReadFile", stringLiteral(configFilePath(category, fileFormat)))},
+		},
+		errorReturnStmt(),
+		newConfigStmt(category),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{selectorCall("yaml", "Unmarshal", ast.NewIdent("contents"), ast.NewIdent("config"))},
+		},
+		errorReturnStmt(),
+	}
+}
+
+func (yamlBackend) Declarations(string, configFormat) []ast.Decl { return nil }
+
+func (yamlBackend) Helpers() map[string]string { return nil }
+
+func (yamlBackend) Environment() bool { return false }
+
+// envBackend reads the environment, using the category's dotenv file for the variables which aren't set
+type envBackend struct{}
+
+const envModule = "github.com/caarlos0/env/v11"
+
+const envFileHelperName = "env_file.go"
+
+const envFileHelperContents = `package config
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// readEnvFile returns the variables of the dotenv file with the prefix added, overridden by the
+// variables set in the environment
+func readEnvFile(path string, prefix string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	environment := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, found := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !found {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		environment[prefix+strings.TrimSpace(key)] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		environment[key] = value
+	}
+	return environment, nil
+}
+`
+
+func (envBackend) Name() string { return "env" }
+
+func (envBackend) Formats() []string { return []string{"dotenv"} }
+
+func (envBackend) Modules(configFormat) []string { return []string{envModule} }
+
+func (envBackend) Imports(configFormat) []string { return []string{envModule} }
+
+func (envBackend) StructTag(fileFormat configFormat, property string) string {
+	return fileFormat.StructTag(property)
+}
+
+func (envBackend) LoadStatements(category string, fileFormat configFormat) []ast.Stmt {
+	prefix := envVarName(category, nil) + "_"
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("environment"), ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun:  ast.NewIdent("readEnvFile"),
+				Args: []ast.Expr{stringLiteral(configFilePath(category, fileFormat)), stringLiteral(prefix)},
+			}},
+		},
+		errorReturnStmt(),
+		newConfigStmt(category),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{selectorCall("env", "ParseWithOptions", ast.NewIdent("config"), &ast.CompositeLit{
+				Type: &ast.SelectorExpr{X: ast.NewIdent("env"), Sel: ast.NewIdent("Options")},
+				Elts: []ast.Expr{
+					&ast.KeyValueExpr{Key: ast.NewIdent("Prefix"), Value: stringLiteral(prefix)},
+					&ast.KeyValueExpr{Key: ast.NewIdent("Environment"), Value: ast.NewIdent("environment")},
+				},
+			})},
+		},
+		errorReturnStmt(),
+	}
+}
+
+func (envBackend) Declarations(string, configFormat) []ast.Decl { return nil }
+
+func (envBackend) Helpers() map[string]string {
+	return map[string]string{envFileHelperName: envFileHelperContents}
+}
+
+func (envBackend) Environment() bool { return true }
+
+func selectorCall(pkg string, function string, args ...ast.Expr) *ast.CallExpr {
+	return &ast.CallExpr{
+		Fun:  &ast.SelectorExpr{X: ast.NewIdent(pkg), Sel: ast.NewIdent(function)},
+		Args: args,
+	}
+}
+
+func stringLiteral(value string) *ast.BasicLit {
+	return &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(value)}
+}
+
+// errorReturnStmt returns if err != nil { return nil, err }
+func errorReturnStmt() *ast.IfStmt {
+	return &ast.IfStmt{
+		Cond: &ast.BinaryExpr{
+			X:  &ast.Ident{Name: "err"},
+			Op: token.NEQ,
+			Y:  ast.NewIdent("nil"),
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("nil"), &ast.Ident{Name: "err"}}},
+		}},
+	}
+}
+
+// newConfigStmt returns config := &CategoryConfig{}
+func newConfigStmt(category string) *ast.AssignStmt {
+	return &ast.AssignStmt{
+		Lhs: []ast.Expr{&ast.Ident{Name: "config"}},
+		Tok: token.DEFINE,
+		Rhs: []ast.Expr{
+			&ast.UnaryExpr{
+				Op: token.AND,
+				X: &ast.CompositeLit{
+					Type: &ast.Ident{Name: categoryStructName(category)},
+				},
+			},
+		},
+	}
+}
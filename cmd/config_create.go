@@ -8,13 +8,16 @@ import (
 	"go/format"
 	"go/printer"
 	"go/token"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 	"golang.org/x/tools/go/ast/astutil"
 	"os"
 	"strings"
 )
 
+var categoryFormat string
+var categoryWatch bool
+var categoryFlags bool
+var categorySource string
+
 // createCmd represents the create command
 var createCmd = &cobra.Command{
 	Use:   "create",
@@ -26,35 +29,165 @@ and a new struct inside of this file.`,
 			return fmt.Errorf("unexpected length of argument list")
 		}
 		categoryName := strings.ToLower(args[0])
-		if exists, err := categoryExists(categoryName); exists || err != nil {
-			if err != nil {
-				return err
-			}
-			return fmt.Errorf("category %s already exists", categoryName)
+		return createCategoryWithOptions(categoryName, categoryOptions{
+			Format: categoryFormat,
+			Watch:  categoryWatch,
+			Flags:  categoryFlags,
+			Source: categorySource,
+		})
+	},
+}
+
+// categoryOptions are the choices of config create
+type categoryOptions struct {
+	// Format of the category's file, empty for the project's format
+	Format string
+	Watch  bool
+	Flags  bool
+	// Source is the kind of source the category is loaded from, empty to read its file directly
+	Source string
+}
+
+// createCategoryWithOptions creates the category after checking the name is free and the options fit the project
+func createCategoryWithOptions(categoryName string, options categoryOptions) error {
+	err := checkCategoryName(categoryName)
+	if err != nil {
+		return err
+	}
+	if exists, err := categoryExists(categoryName); exists || err != nil {
+		if err != nil {
+			return err
+		}
+		return fmt.Errorf("category %s already exists", categoryName)
+	}
+	err = checkCategorySourcesFree(categoryName, options)
+	if err != nil {
+		return err
+	}
+	fileFormat, err := projectConfigFormat(options.Format)
+	if err != nil {
+		return err
+	}
+	backend, err := projectConfigBackend()
+	if err != nil {
+		return err
+	}
+	err = checkBackendFormat(backend, fileFormat)
+	if err != nil {
+		return err
+	}
+	err = checkCategoryOptions(backend, options)
+	if err != nil {
+		return err
+	}
+	if options.Source != "" {
+		backend, err = newSourceBackend(backend, options.Source)
+		if err != nil {
+			return err
+		}
+	}
+	err = createCategory(categoryName, fileFormat, backend)
+	if err != nil {
+		return err
+	}
+	return enableCategoryOptions(categoryName, options)
+}
+
+// enableCategoryOptions generates the loader and the flags the options ask for next to the created category
+func enableCategoryOptions(category string, options categoryOptions) error {
+	if options.Watch {
+		fileFormat, found := findCategoryFormat(category)
+		if !found {
+			return fmt.Errorf("config file of category %s not found", category)
 		}
-		err := createCategory(categoryName)
+		err := createCategoryLoader(category, fileFormat)
 		if err != nil {
 			return err
 		}
+	}
+	if options.Flags {
+		err := enableCategoryFlags(category)
+		if err != nil {
+			return err
+		}
+		return generateCategoryFlags(category)
+	}
+	return nil
+}
+
+// checkCategoryName fails for names which aren't identifiers or whose files would be the files generated
+// next to the categories
+func checkCategoryName(name string) error {
+	err := checkConfigName("category", name)
+	if err != nil {
+		return err
+	}
+	if reservedCategoryNames[name] {
+		return fmt.Errorf("category name %s is reserved", name)
+	}
+	for _, suffix := range reservedCategorySuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return fmt.Errorf("category name %s is reserved, names ending in %s are the files generated for other categories", name, suffix)
+		}
+	}
+	return nil
+}
+
+// checkCategorySourcesFree fails when a file the category generates in the config package exists already,
+// belonging to another category
+func checkCategorySourcesFree(category string, options categoryOptions) error {
+	paths := []string{configSourcePath(category), categoryTestPath(category)}
+	if options.Watch {
+		paths = append(paths, categoryLoaderPath(category))
+	}
+	if options.Flags {
+		paths = append(paths, categoryFlagsPath(category))
+	}
+	for _, path := range paths {
+		_, err := os.Stat(path)
+		if err == nil {
+			return fmt.Errorf("%s exists already, it isn't a file of category %s", path, category)
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkCategoryOptions fails when the options need another config library or don't go together
+func checkCategoryOptions(backend configBackend, options categoryOptions) error {
+	if options.Watch && backend.Name() != "viper" {
+		return fmt.Errorf("--watch needs the viper config library, the project uses %s", backend.Name())
+	}
+	if options.Flags && backend.Name() != "viper" {
+		return fmt.Errorf("--flags needs the viper config library, the project uses %s", backend.Name())
+	}
+	if options.Source == "" {
 		return nil
-	},
+	}
+	if options.Watch {
+		return fmt.Errorf("--watch reloads the category's file and can't be combined with --source")
+	}
+	_, err := newSourceBackend(backend, options.Source)
+	return err
 }
 
-func createCategory(name string) error {
-	// create yaml file
-	configFilePath := fmt.Sprintf("config/%s.yaml", name)
-	yaml, err := os.Create(configFilePath)
+func createCategory(name string, fileFormat configFormat, backend configBackend) error {
+	err := requireModules(backend.Modules(fileFormat)...)
 	if err != nil {
 		return err
 	}
-	err = yaml.Close()
+	// create config file
+	filePath := configFilePath(name, fileFormat)
+	err = os.WriteFile(filePath, fileFormat.InitialContents(), 0644)
 	if err != nil {
 		return err
 	}
 
 	// begin AST
 	fset := token.NewFileSet()
-	structName := cases.Title(language.Und).String(name) + "Config"
+	structName := categoryStructName(name)
 	file := &ast.File{Name: &ast.Ident{Name: name}}
 
 	// create struct
@@ -69,79 +202,39 @@ func createCategory(name string) error {
 	file.Decls = []ast.Decl{typeDeclaration}
 
 	// create struct constructor
-	errorHandlingStmt := &ast.IfStmt{
-		Cond: &ast.BinaryExpr{
-			X:  &ast.Ident{Name: "err"},
-			Op: token.NEQ,
-			Y:  ast.NewIdent("nil"),
+	body := backend.LoadStatements(name, fileFormat)
+	body = append(body,
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun:  &ast.Ident{Name: "validate"},
+				Args: []ast.Expr{&ast.Ident{Name: "config"}},
+			}},
 		},
-		Body: &ast.BlockStmt{List: []ast.Stmt{
-			&ast.ReturnStmt{Results: []ast.Expr{ast.NewIdent("nil"), &ast.Ident{Name: "err"}}},
+		errorReturnStmt(),
+		&ast.ReturnStmt{Results: []ast.Expr{
+			&ast.Ident{Name: "config"},
+			ast.NewIdent("nil"),
 		}},
-	}
+	)
 	funcDefinition := &ast.FuncDecl{
 		Doc: &ast.CommentGroup{List: []*ast.Comment{
-			{Text: fmt.Sprintf("// New%s unmarshalls yaml data to struct and returns a pointer to it", structName)},
+			{Text: fmt.Sprintf("// New%s unmarshalls %s data to struct, validates it and returns a pointer to it", structName, fileFormat.Name())},
 		}},
 		Name: &ast.Ident{Name: fmt.Sprintf("New%s", structName)},
 		Type: &ast.FuncType{Results: &ast.FieldList{List: []*ast.Field{
 			{Type: &ast.StarExpr{X: &ast.Ident{Name: structName}}},
 			{Type: ast.NewIdent("error")},
 		}}},
-		Body: &ast.BlockStmt{List: []ast.Stmt{
-			&ast.ExprStmt{X: &ast.CallExpr{
-				Fun: &ast.SelectorExpr{
-					X:   &ast.Ident{Name: "viper"},
-					Sel: &ast.Ident{Name: "SetConfigFile"},
-				},
-				Args: []ast.Expr{
-					&ast.BasicLit{Kind: token.STRING, Value: fmt.Sprintf("\"%s\"", configFilePath)},
-				},
-			}},
-			&ast.AssignStmt{
-				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
-				Tok: token.DEFINE,
-				Rhs: []ast.Expr{&ast.CallExpr{Fun: &ast.SelectorExpr{
-					X:   &ast.Ident{Name: "viper"},
-					Sel: &ast.Ident{Name: "ReadInConfig"},
-				}}},
-			},
-			errorHandlingStmt,
-			&ast.AssignStmt{
-				Lhs: []ast.Expr{&ast.Ident{Name: "config"}},
-				Tok: token.DEFINE,
-				Rhs: []ast.Expr{
-					&ast.UnaryExpr{
-						Op: token.AND,
-						X: &ast.CompositeLit{
-							Type: &ast.Ident{Name: structName},
-						},
-					},
-				},
-			},
-			&ast.AssignStmt{
-				Lhs: []ast.Expr{&ast.Ident{Name: "err"}},
-				Tok: token.ASSIGN,
-				Rhs: []ast.Expr{&ast.CallExpr{
-					Fun: &ast.SelectorExpr{
-						X:   &ast.Ident{Name: "viper"},
-						Sel: &ast.Ident{Name: "Unmarshal"},
-					},
-					Args: []ast.Expr{
-						&ast.Ident{Name: "config"},
-					},
-				}},
-			},
-			errorHandlingStmt,
-			&ast.ReturnStmt{Results: []ast.Expr{
-				&ast.Ident{Name: "config"},
-				ast.NewIdent("nil"),
-			}},
-		}},
+		Body: &ast.BlockStmt{List: body},
 	}
 	file.Decls = append(file.Decls, funcDefinition)
+	file.Decls = append(file.Decls, backend.Declarations(name, fileFormat)...)
 	file.Name = &ast.Ident{Name: "config"}
-	astutil.AddImport(fset, file, "github.com/spf13/viper")
+	for _, path := range backend.Imports(fileFormat) {
+		astutil.AddImport(fset, file, path)
+	}
 	var code bytes.Buffer
 	err = printer.Fprint(&code, fset, file)
 	if err != nil {
@@ -151,13 +244,33 @@ func createCategory(name string) error {
 	if err != nil {
 		return fmt.Errorf("failed to format code: %w", err)
 	}
-	err = os.WriteFile(fmt.Sprintf("pkg/infra/config/%s.go", name), formattedCode, 0644)
+	err = os.WriteFile(configSourcePath(name), formattedCode, 0644)
 	if err != nil {
 		return fmt.Errorf("failed to write to source file: %w", err)
 	}
-	return nil
+	err = ensureValidationSource()
+	if err != nil {
+		return err
+	}
+	err = ensureBackendHelpers(backend)
+	if err != nil {
+		return err
+	}
+	err = ensureRootConfig()
+	if err != nil {
+		return err
+	}
+	err = addCategoryToRoot(name)
+	if err != nil {
+		return err
+	}
+	return generateCategoryTests(name)
 }
 
 func init() {
 	configCmd.AddCommand(createCmd)
+	createCmd.Flags().StringVar(&categorySource, "source", "", fmt.Sprintf("Load the category from a replaceable Source of the kind (%s) instead of its file", strings.Join(configSourceKindNames(), ", ")))
+	createCmd.Flags().BoolVar(&categoryFlags, "flags", false, "Generate a command-line flag for every property added to the category")
+	createCmd.Flags().BoolVar(&categoryWatch, "watch", false, "Generate a loader reloading the category when its config file changes")
+	createCmd.Flags().StringVar(&categoryFormat, "format", "", fmt.Sprintf("Format of the category's config file (%s), defaults to the project's format", strings.Join(configFormatNames(), ", ")))
 }
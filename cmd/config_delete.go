@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// deleteCmd represents the delete command
+var deleteCmd = &cobra.Command{
+	Use:   "delete [category_name]",
+	Short: "Delete a command set",
+	Long: `Delete a command set: the category's config file, its source files and its field
+in the root config.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		category := strings.ToLower(args[0])
+		if category == "application" {
+			return fmt.Errorf("the application category is used by the entrypoints and can't be deleted")
+		}
+		if exists, err := categoryExists(category); !exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("the category %v doesn't exist", category)
+		}
+		return deleteCategory(category)
+	},
+}
+
+func deleteCategory(name string) error {
+	err := ensureRootConfig()
+	if err != nil {
+		return err
+	}
+	err = removeCategoryFromRoot(name)
+	if err != nil {
+		return err
+	}
+	err = disableCategoryFlags(name)
+	if err != nil {
+		return err
+	}
+	paths := []string{configSourcePath(name), categoryLoaderPath(name), categoryFlagsPath(name), categoryTestPath(name)}
+	if fileFormat, found := findCategoryFormat(name); found {
+		paths = append(paths, configFilePath(name, fileFormat))
+	}
+	for _, path := range paths {
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	configCmd.AddCommand(deleteCmd)
+}
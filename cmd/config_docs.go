@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/types"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	docsFormatMarkdown = "markdown"
+	docsFormatHtml     = "html"
+)
+
+var docsFormat string
+var docsOutput string
+
+// docsCmd represents the docs command
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate the configuration reference",
+	Long: `Generate the configuration reference of all categories from the structs in pkg/infra/config.
+For every property it lists the key, the environment variable when the project's config library
+reads the environment, the type, the default value, whether it is required and its description.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		render, ok := map[string]func(io.Writer, []categoryReference) error{
+			docsFormatMarkdown: renderMarkdownReference,
+			docsFormatHtml:     renderHtmlReference,
+		}[docsFormat]
+		if !ok {
+			return fmt.Errorf("unsupported docs format %q, expected %s or %s", docsFormat, docsFormatMarkdown, docsFormatHtml)
+		}
+		categories, err := listCategories()
+		if err != nil {
+			return err
+		}
+		var references []categoryReference
+		for _, category := range categories {
+			reference, err := buildCategoryReference(category)
+			if err != nil {
+				return fmt.Errorf("failed to document category %s: %w", category, err)
+			}
+			references = append(references, reference)
+		}
+		var buf bytes.Buffer
+		err = render(&buf, references)
+		if err != nil {
+			return err
+		}
+		output := docsOutput
+		if output == "" {
+			output = map[string]string{docsFormatMarkdown: "docs/configuration.md", docsFormatHtml: "docs/configuration.html"}[docsFormat]
+		}
+		if output == "-" {
+			_, err = buf.WriteTo(cmd.OutOrStdout())
+			return err
+		}
+		err = os.MkdirAll(filepath.Dir(output), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(output, buf.Bytes(), 0644)
+	},
+}
+
+type categoryReference struct {
+	Name string
+	File string
+	// Environment tells whether the properties can be overridden by their environment variables
+	Environment bool
+	Properties  []propertyReference
+}
+
+type propertyReference struct {
+	Key         string
+	EnvVar      string
+	Type        string
+	Default     string
+	Required    bool
+	Secret      bool
+	Description string
+}
+
+func buildCategoryReference(category string) (categoryReference, error) {
+	reference := categoryReference{Name: category}
+	if fileFormat, found := findCategoryFormat(category); found {
+		reference.File = configFilePath(category, fileFormat)
+	}
+	source, err := parseConfigSource(category)
+	if err != nil {
+		return reference, err
+	}
+	structType := source.categoryStruct(category)
+	if structType == nil {
+		return reference, fmt.Errorf("struct %s not found", categoryStructName(category))
+	}
+	types, err := packageTypes()
+	if err != nil {
+		return reference, err
+	}
+	backend, err := projectConfigBackend()
+	if err != nil {
+		return reference, err
+	}
+	reference.Environment = backend.Environment()
+	reference.Properties = structReference(category, nil, structType, types)
+	return reference, nil
+}
+
+// structReference lists the properties of the struct, descending into nested structs
+func structReference(category string, path []string, structType *ast.StructType, packageTypes map[string]ast.Expr) []propertyReference {
+	var properties []propertyReference
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			propertyPath := append(append([]string{}, path...), propertyKey(name.Name, tag))
+			if nested := nestedStruct(field.Type, packageTypes); nested != nil {
+				properties = append(properties, structReference(category, propertyPath, nested, packageTypes)...)
+				continue
+			}
+			_, required := validationRules(tag)["required"]
+			properties = append(properties, propertyReference{
+				Key:         strings.Join(propertyPath, "."),
+				EnvVar:      envVarName(category, propertyPath),
+				Type:        types.ExprString(field.Type),
+				Default:     tag.Get("default"),
+				Required:    required,
+				Secret:      tag.Get("secret") == "true",
+				Description: fieldDescription(field),
+			})
+		}
+	}
+	return properties
+}
+
+func nestedStruct(expr ast.Expr, packageTypes map[string]ast.Expr) *ast.StructType {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t
+	case *ast.StarExpr:
+		return nestedStruct(t.X, packageTypes)
+	case *ast.Ident:
+		if structType, ok := packageTypes[t.Name].(*ast.StructType); ok {
+			return structType
+		}
+	}
+	return nil
+}
+
+// envVarName returns the environment variable name of the property, e.g. DATABASE_SERVER_PORT
+func envVarName(category string, path []string) string {
+	return strings.ToUpper(strings.Join(append([]string{category}, path...), "_"))
+}
+
+// fieldDescription returns the doc comment of the field as a single line
+func fieldDescription(field *ast.Field) string {
+	if field.Doc == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(field.Doc.Text()), " ")
+}
+
+// validationRules splits the validate tag into rule names and their arguments
+func validationRules(tag reflect.StructTag) map[string]string {
+	rules := map[string]string{}
+	for _, rule := range strings.Split(tag.Get("validate"), ",") {
+		name, argument, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if name != "" {
+			rules[name] = argument
+		}
+	}
+	return rules
+}
+
+var markdownReferenceTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"cell": markdownCell,
+}).Parse(`# Configuration reference
+{{range $category := .}}
+## {{.Name}}
+{{if .File}}
+File: ` + "`{{.File}}`" + `
+{{end}}
+| Key |{{if .Environment}} Environment variable |{{end}} Type | Default | Required | Description |
+|-----|{{if .Environment}}----------------------|{{end}}------|---------|----------|-------------|
+{{range .Properties}}| ` + "`{{.Key}}` |{{if $category.Environment}} `{{.EnvVar}}` |{{end}} `{{.Type}}`" + ` | {{cell .Default}} | {{if .Required}}yes{{else}}no{{end}} | {{if .Secret}}**Secret.** {{end}}{{cell .Description}} |
+{{end}}{{end}}`))
+
+func markdownCell(value string) string {
+	return strings.ReplaceAll(value, "|", `\|`)
+}
+
+func renderMarkdownReference(w io.Writer, references []categoryReference) error {
+	return markdownReferenceTemplate.Execute(w, references)
+}
+
+var htmlReferenceTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Configuration reference</title>
+</head>
+<body>
+<h1>Configuration reference</h1>
+{{range $category := .}}
+<h2>{{.Name}}</h2>
+{{if .File}}<p>File: <code>{{.File}}</code></p>{{end}}
+<table>
+<thead>
+<tr><th>Key</th>{{if .Environment}}<th>Environment variable</th>{{end}}<th>Type</th><th>Default</th><th>Required</th><th>Description</th></tr>
+</thead>
+<tbody>
+{{range .Properties}}<tr><td><code>{{.Key}}</code></td>{{if $category.Environment}}<td><code>{{.EnvVar}}</code></td>{{end}}<td><code>{{.Type}}</code></td><td>{{.Default}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{if .Secret}}<strong>Secret.</strong> {{end}}{{.Description}}</td></tr>
+{{end}}</tbody>
+</table>
+{{end}}</body>
+</html>
+`))
+
+func renderHtmlReference(w io.Writer, references []categoryReference) error {
+	return htmlReferenceTemplate.Execute(w, references)
+}
+
+func init() {
+	configCmd.AddCommand(docsCmd)
+	docsCmd.Flags().StringVarP(&docsFormat, "format", "f", docsFormatMarkdown, "Format of the reference, markdown or html")
+	docsCmd.Flags().StringVarP(&docsOutput, "out", "o", "", "File the reference is written to, - for the standard output. Defaults to docs/configuration.md or docs/configuration.html")
+	docsCmd.MarkFlagFilename("out")
+}
@@ -0,0 +1,326 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"os"
+	"strconv"
+	"strings"
+	"template/config"
+	"text/template"
+)
+
+// flagMethods maps the field types which can be set by a flag to the pflag method defining the flag
+var flagMethods = map[string]string{
+	"string":        "String",
+	"bool":          "Bool",
+	"int":           "Int",
+	"int8":          "Int8",
+	"int16":         "Int16",
+	"int32":         "Int32",
+	"int64":         "Int64",
+	"uint":          "Uint",
+	"uint8":         "Uint8",
+	"uint16":        "Uint16",
+	"uint32":        "Uint32",
+	"uint64":        "Uint64",
+	"float32":       "Float32",
+	"float64":       "Float64",
+	"time.Duration": "Duration",
+	"[]string":      "StringSlice",
+	"[]int":         "IntSlice",
+	"[]bool":        "BoolSlice",
+}
+
+var flagsTemplate = template.Must(template.New("flags").Parse(`package config
+
+import (
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+var {{.Var}} *pflag.FlagSet
+
+// Bind{{.Name}}Flags defines the flags overriding the properties of {{.Struct}}. Call it before the flags are parsed,
+// New{{.Struct}} then prefers the flags set on the command line to the environment and {{.File}}.
+func Bind{{.Name}}Flags(fs *pflag.FlagSet) {
+{{- range .Flags}}
+	fs.{{.Method}}({{printf "%q" .Name}}, {{.Default}}, {{printf "%q" .Usage}})
+{{- end}}
+	{{.Var}} = fs
+}
+
+// bind{{.Name}}Flags makes v read the properties from the flags defined by Bind{{.Name}}Flags
+func bind{{.Name}}Flags(v *viper.Viper) error {
+	if {{.Var}} == nil {
+		return nil
+	}
+	for key, name := range map[string]string{
+{{- range .Flags}}
+		{{printf "%q" .Key}}: {{printf "%q" .Name}},
+{{- end}}
+	} {
+		err := v.BindPFlag(key, {{.Var}}.Lookup(name))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`))
+
+// propertyFlag is a flag overriding a property
+type propertyFlag struct {
+	Name    string
+	Key     string
+	Method  string
+	Default string
+	Usage   string
+}
+
+func categoryFlagsPath(category string) string {
+	return fmt.Sprintf("%s/%s_flags.go", config.ConfigSourceDirectory, category)
+}
+
+// flagName derives the flag's name from the property's keys, e.g. database-server-port
+func flagName(category string, path []string) string {
+	return strings.ToLower(strings.Join(append([]string{category}, path...), "-"))
+}
+
+// categoryHasFlags reports whether the properties added to the category get flags without asking
+func categoryHasFlags(category string) (bool, error) {
+	manifest, err := readManifest()
+	if err != nil {
+		return false, err
+	}
+	for _, name := range manifest.FlagCategories {
+		if name == category {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// enableCategoryFlags records in the manifest that every property of the category gets a flag
+func enableCategoryFlags(category string) error {
+	if enabled, err := categoryHasFlags(category); enabled || err != nil {
+		return err
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	manifest.FlagCategories = append(manifest.FlagCategories, category)
+	return writeManifest(manifest)
+}
+
+// disableCategoryFlags forgets that the properties of the category get flags
+func disableCategoryFlags(category string) error {
+	if enabled, err := categoryHasFlags(category); !enabled || err != nil {
+		return err
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return err
+	}
+	categories := manifest.FlagCategories[:0]
+	for _, name := range manifest.FlagCategories {
+		if name != category {
+			categories = append(categories, name)
+		}
+	}
+	manifest.FlagCategories = categories
+	return writeManifest(manifest)
+}
+
+// checkFlagType fails unless a flag can hold a value of the type
+func checkFlagType(typeName string) error {
+	if _, ok := flagMethods[typeName]; !ok {
+		return fmt.Errorf("properties of type %s can't be set by a flag", typeName)
+	}
+	return nil
+}
+
+// callsFunction reports whether the function refers to the function named name
+func callsFunction(function *ast.FuncDecl, name string) bool {
+	var found bool
+	ast.Inspect(function.Body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// collectFlags returns the flags of the fields with a flag tag, including the ones in sections
+func collectFlags(structType *ast.StructType, keys []string) []propertyFlag {
+	var flags []propertyFlag
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			path := append(append([]string{}, keys...), strings.ToLower(propertyKey(name.Name, tag)))
+			if nested, ok := field.Type.(*ast.StructType); ok {
+				flags = append(flags, collectFlags(nested, path)...)
+				continue
+			}
+			flag, ok := tag.Lookup("flag")
+			if !ok {
+				continue
+			}
+			method := flagMethods[types.ExprString(field.Type)]
+			if method == "" {
+				continue
+			}
+			usage := fieldDescription(field)
+			if usage == "" {
+				usage = fmt.Sprintf("Overrides %s", strings.Join(path, "."))
+			}
+			flags = append(flags, propertyFlag{
+				Name:    flag,
+				Key:     strings.Join(path, "."),
+				Method:  method,
+				Default: flagDefault(method, tag.Get("default")),
+				Usage:   usage,
+			})
+		}
+	}
+	return flags
+}
+
+// flagDefault returns the Go literal of the flag's default value. Viper only falls back to it when neither
+// the environment nor the config file set the property.
+func flagDefault(method string, value string) string {
+	switch method {
+	case "String":
+		return strconv.Quote(value)
+	case "Bool":
+		if _, err := strconv.ParseBool(value); err == nil {
+			return value
+		}
+		return "false"
+	case "Duration":
+		return "0"
+	case "StringSlice", "IntSlice", "BoolSlice":
+		return "nil"
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "0"
+}
+
+// generateCategoryFlags writes the flag definitions of the category's flagged properties and makes the
+// category's constructor bind them
+func generateCategoryFlags(category string) error {
+	backend, err := projectConfigBackend()
+	if err != nil {
+		return err
+	}
+	if backend.Name() != "viper" {
+		return fmt.Errorf("flags need the viper config library, the project uses %s", backend.Name())
+	}
+	source, err := parseConfigSource(category)
+	if err != nil {
+		return err
+	}
+	structType := source.categoryStruct(category)
+	if structType == nil {
+		return fmt.Errorf("struct %s not found", categoryStructName(category))
+	}
+	flags := collectFlags(structType, nil)
+	fileFormat, found := findCategoryFormat(category)
+	if !found {
+		return fmt.Errorf("config file of category %s not found", category)
+	}
+	name := fieldName(category)
+	var code bytes.Buffer
+	err = flagsTemplate.Execute(&code, map[string]any{
+		"Name":   name,
+		"Var":    category + "Flags",
+		"Struct": categoryStructName(category),
+		"File":   configFilePath(category, fileFormat),
+		"Flags":  flags,
+	})
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	err = os.WriteFile(categoryFlagsPath(category), formattedCode, 0644)
+	if err != nil {
+		return err
+	}
+	err = requireModules("github.com/spf13/pflag")
+	if err != nil {
+		return err
+	}
+	return bindFlagsInConstructor(source, category)
+}
+
+// bindFlagsInConstructor calls bind<Category>Flags in new<Category>Viper, which the constructor and the loader
+// of the category share, or else in the constructor, before the config is unmarshalled
+func bindFlagsInConstructor(source *configSource, category string) error {
+	bindFunc := fmt.Sprintf("bind%sFlags", fieldName(category))
+	constructor := source.constructor(category)
+	if constructor == nil {
+		return fmt.Errorf("constructor New%s not found", categoryStructName(category))
+	}
+	for _, function := range []*ast.FuncDecl{constructor, source.function(categoryViperName(category))} {
+		if function != nil && callsFunction(function, bindFunc) {
+			return nil
+		}
+	}
+	if setup := source.function(categoryViperName(category)); setup != nil && len(setup.Body.List) > 0 {
+		last := len(setup.Body.List) - 1
+		bindStatement := &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(bindFunc), Args: []ast.Expr{ast.NewIdent("v")}}},
+		}
+		errorCheck := errorReturnStmt()
+		placeAt(bindStatement, setup.Body.List[last].Pos())
+		placeAt(errorCheck, setup.Body.List[last].Pos())
+		list := append([]ast.Stmt{}, setup.Body.List[:last]...)
+		setup.Body.List = append(list, bindStatement, errorCheck, setup.Body.List[last])
+		return source.write()
+	}
+	for i, statement := range constructor.Body.List {
+		assignment, ok := statement.(*ast.AssignStmt)
+		if !ok || len(assignment.Rhs) != 1 {
+			continue
+		}
+		call, ok := assignment.Rhs[0].(*ast.CallExpr)
+		if !ok {
+			continue
+		}
+		selector, ok := call.Fun.(*ast.SelectorExpr)
+		if !ok || selector.Sel.Name != "Unmarshal" {
+			continue
+		}
+		// the categories generated before viper instances were used read the global one
+		instance := selector.X
+		if ident, ok := instance.(*ast.Ident); ok && ident.Name == "viper" {
+			instance = selectorCall("viper", "GetViper")
+		}
+		bindStatement := &ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(bindFunc), Args: []ast.Expr{instance}}},
+		}
+		errorCheck := errorReturnStmt()
+		placeAt(bindStatement, statement.Pos())
+		placeAt(errorCheck, statement.Pos())
+		list := append([]ast.Stmt{}, constructor.Body.List[:i]...)
+		list = append(list, bindStatement, errorCheck)
+		constructor.Body.List = append(list, constructor.Body.List[i:]...)
+		return source.write()
+	}
+	return fmt.Errorf("New%s doesn't unmarshal the config with viper", categoryStructName(category))
+}
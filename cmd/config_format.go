@@ -0,0 +1,532 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"template/config"
+)
+
+// configFormat describes how the configuration file of a category is stored on disk
+type configFormat interface {
+	Name() string
+	Extension() string
+	// Key returns the name under which the property is stored in the file
+	Key(property string) string
+	// StructTag returns the tag attached to the generated struct field, without the backticks
+	StructTag(property string) string
+	InitialContents() []byte
+	// SetProperty inserts or updates the value under the path of property keys
+	SetProperty(filePath string, path []string, value any) error
+	// RemoveProperty removes the value under the path of property keys
+	RemoveProperty(filePath string, path []string) error
+}
+
+var configFormats = map[string]configFormat{
+	"yaml":   yamlFormat{},
+	"json":   jsonFormat{},
+	"toml":   tomlFormat{},
+	"dotenv": dotenvFormat{},
+}
+
+func resolveConfigFormat(name string) (configFormat, error) {
+	format, ok := configFormats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported config format %q, expected one of: %s", name, strings.Join(configFormatNames(), ", "))
+	}
+	return format, nil
+}
+
+func configFormatNames() []string {
+	names := make([]string, 0, len(configFormats))
+	for name := range configFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// projectConfigFormat returns the format requested with a flag, falling back to the one recorded in the manifest
+func projectConfigFormat(requested string) (configFormat, error) {
+	if requested != "" {
+		return resolveConfigFormat(requested)
+	}
+	manifest, err := readManifest()
+	if err != nil {
+		return nil, err
+	}
+	return resolveConfigFormat(manifest.ConfigFormat)
+}
+
+// findCategoryFormat looks for the configuration file of the category in any of the supported formats
+func findCategoryFormat(category string) (configFormat, bool) {
+	for _, name := range configFormatNames() {
+		format := configFormats[name]
+		if _, err := os.Stat(configFilePath(category, format)); err == nil {
+			return format, true
+		}
+	}
+	return nil, false
+}
+
+func configFilePath(category string, format configFormat) string {
+	return fmt.Sprintf("%s/%s.%s", config.ConfigFileDirectory, category, format.Extension())
+}
+
+func configSourcePath(category string) string {
+	return fmt.Sprintf("%s/%s.go", config.ConfigSourceDirectory, category)
+}
+
+type yamlFormat struct{}
+
+func (yamlFormat) Name() string                { return "yaml" }
+func (yamlFormat) Extension() string           { return "yaml" }
+func (yamlFormat) Key(property string) string  { return property }
+func (yamlFormat) InitialContents() []byte     { return nil }
+func (f yamlFormat) StructTag(p string) string { return fmt.Sprintf("yaml:\"%s\"", f.Key(p)) }
+
+func (f yamlFormat) SetProperty(filePath string, path []string, value any) error {
+	document, err := loadYamlDocument(filePath)
+	if err != nil {
+		return err
+	}
+	err = document.Set(path, value)
+	if err != nil {
+		return err
+	}
+	return document.Save(filePath)
+}
+
+func (f yamlFormat) RemoveProperty(filePath string, path []string) error {
+	document, err := loadYamlDocument(filePath)
+	if err != nil {
+		return err
+	}
+	_, err = document.Delete(path)
+	if err != nil {
+		return err
+	}
+	return document.Save(filePath)
+}
+
+type jsonFormat struct{}
+
+func (jsonFormat) Name() string                { return "json" }
+func (jsonFormat) Extension() string           { return "json" }
+func (jsonFormat) Key(property string) string  { return property }
+func (jsonFormat) InitialContents() []byte     { return []byte("{}\n") }
+func (f jsonFormat) StructTag(p string) string { return fmt.Sprintf("json:\"%s\"", f.Key(p)) }
+
+func (f jsonFormat) SetProperty(filePath string, path []string, value any) error {
+	return f.edit(filePath, func(properties *jsonObject) error {
+		for i, key := range path[:len(path)-1] {
+			nested, ok := properties.values[key].(*jsonObject)
+			if !ok {
+				if _, exists := properties.values[key]; exists {
+					return fmt.Errorf("key %s is not an object", strings.Join(path[:i+1], "."))
+				}
+				nested = newJsonObject()
+				properties.set(key, nested)
+			}
+			properties = nested
+		}
+		properties.set(path[len(path)-1], value)
+		return nil
+	})
+}
+
+func (f jsonFormat) RemoveProperty(filePath string, path []string) error {
+	return f.edit(filePath, func(properties *jsonObject) error {
+		removeJsonKey(properties, path)
+		return nil
+	})
+}
+
+// edit decodes the file keeping the order of the keys of its objects, so that the keys the change leaves
+// alone stay where they are
+func (f jsonFormat) edit(filePath string, change func(*jsonObject) error) error {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+	properties := newJsonObject()
+	if len(strings.TrimSpace(string(contents))) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(contents))
+		decoder.UseNumber()
+		value, err := decodeJsonValue(decoder)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", filePath, err)
+		}
+		object, ok := value.(*jsonObject)
+		if !ok {
+			return fmt.Errorf("failed to parse %s: the file doesn't hold an object", filePath)
+		}
+		properties = object
+	}
+	err = change(properties)
+	if err != nil {
+		return err
+	}
+	contents, err = json.MarshalIndent(properties, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, append(contents, '\n'), 0644)
+}
+
+// jsonObject is a JSON object keeping its keys in the order of the file
+type jsonObject struct {
+	keys   []string
+	values map[string]any
+}
+
+func newJsonObject() *jsonObject {
+	return &jsonObject{values: map[string]any{}}
+}
+
+// set replaces the value of the key, new keys going last
+func (o *jsonObject) set(key string, value any) {
+	if _, exists := o.values[key]; !exists {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+func (o *jsonObject) delete(key string) {
+	if _, exists := o.values[key]; !exists {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (o *jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(encodedKey)
+		buf.WriteByte(':')
+		buf.Write(encodedValue)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeJsonValue decodes the next value of the decoder, objects as *jsonObject and numbers as json.Number
+func decodeJsonValue(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+	switch delim {
+	case '{':
+		object := newJsonObject()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key.(string), value)
+		}
+		_, err = decoder.Token()
+		return object, err
+	case '[':
+		items := []any{}
+		for decoder.More() {
+			item, err := decodeJsonValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err = decoder.Token()
+		return items, err
+	}
+	return nil, fmt.Errorf("unexpected %v", delim)
+}
+
+// removeJsonKey removes the key under the path, together with objects left empty by the removal
+func removeJsonKey(properties *jsonObject, path []string) {
+	if len(path) == 1 {
+		properties.delete(path[0])
+		return
+	}
+	nested, ok := properties.values[path[0]].(*jsonObject)
+	if !ok {
+		return
+	}
+	removeJsonKey(nested, path[1:])
+	if len(nested.keys) == 0 {
+		properties.delete(path[0])
+	}
+}
+
+type tomlFormat struct{}
+
+func (tomlFormat) Name() string                { return "toml" }
+func (tomlFormat) Extension() string           { return "toml" }
+func (tomlFormat) Key(property string) string  { return property }
+func (tomlFormat) InitialContents() []byte     { return nil }
+func (f tomlFormat) StructTag(p string) string { return fmt.Sprintf("toml:\"%s\"", f.Key(p)) }
+
+// SetProperty replaces the line holding the key, looking into tables as well. New keys are written at the
+// end of the deepest table holding them, or else as dotted keys above the first table header, otherwise
+// they would end up inside of that table.
+func (f tomlFormat) SetProperty(filePath string, path []string, value any) error {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+	key := strings.Join(path, ".")
+	if index, local := findTomlKey(lines, key); index >= 0 {
+		lines[index] = fmt.Sprintf("%s = %s", local, formatTomlValue(value))
+		return writeLines(filePath, lines)
+	}
+	position, depth := findTomlTable(lines, path)
+	if depth == 0 {
+		position = len(lines)
+		for i, l := range lines {
+			if strings.HasPrefix(strings.TrimSpace(l), "[") {
+				position = i
+				break
+			}
+		}
+	}
+	line := fmt.Sprintf("%s = %s", strings.Join(path[depth:], "."), formatTomlValue(value))
+	lines = append(lines[:position], append([]string{line}, lines[position:]...)...)
+	return writeLines(filePath, lines)
+}
+
+// findTomlTable looks for the table whose name is the longest prefix of the key path. It returns the index
+// following the last key of the table and the number of keys of its name, 0 when there's no such table.
+func findTomlTable(lines []string, path []string) (int, int) {
+	position, depth := 0, 0
+	inTable := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if table, array, ok := parseTomlHeader(line); ok {
+			inTable = false
+			if array {
+				continue
+			}
+			name := strings.Split(table, ".")
+			if len(name) < len(path) && len(name) > depth && strings.Join(path[:len(name)], ".") == strings.Join(name, ".") {
+				position, depth, inTable = i+1, len(name), true
+			}
+			continue
+		}
+		if inTable && trimmed != "" {
+			position = i + 1
+		}
+	}
+	return position, depth
+}
+
+// parseTomlHeader returns the dotted name of the table the line opens, without the comment following it, and
+// whether it's an array of tables. ok is false for lines which aren't table headers.
+func parseTomlHeader(line string) (name string, array bool, ok bool) {
+	header, _, _ := strings.Cut(strings.TrimSpace(line), "#")
+	header = strings.TrimSpace(header)
+	if !strings.HasPrefix(header, "[") {
+		return "", false, false
+	}
+	keys := strings.Split(strings.Trim(header, "[]"), ".")
+	for i, key := range keys {
+		keys[i] = strings.TrimSpace(key)
+	}
+	return strings.Join(keys, "."), strings.HasPrefix(header, "[["), true
+}
+
+func (f tomlFormat) RemoveProperty(filePath string, path []string) error {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+	if index, _ := findTomlKey(lines, strings.Join(path, ".")); index >= 0 {
+		lines = append(lines[:index], lines[index+1:]...)
+	}
+	return writeLines(filePath, lines)
+}
+
+// findTomlKey returns the index of the line assigning the dotted key and the key as written on that line
+func findTomlKey(lines []string, key string) (int, string) {
+	table := ""
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if name, _, ok := parseTomlHeader(line); ok {
+			table = name
+			continue
+		}
+		local, _, found := strings.Cut(trimmed, "=")
+		if !found || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		local = strings.TrimSpace(local)
+		full := local
+		if table != "" {
+			full = table + "." + local
+		}
+		if full == key {
+			return i, local
+		}
+	}
+	return -1, ""
+}
+
+func formatTomlValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		formatted := strconv.FormatFloat(v, 'f', -1, 64)
+		if !strings.ContainsAny(formatted, ".eE") {
+			formatted += ".0"
+		}
+		return formatted
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatTomlValue(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, 0, len(v))
+		for _, key := range keys {
+			entries = append(entries, fmt.Sprintf("%s = %s", key, formatTomlValue(v[key])))
+		}
+		if len(entries) == 0 {
+			return "{}"
+		}
+		return "{ " + strings.Join(entries, ", ") + " }"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+type dotenvFormat struct{}
+
+func (dotenvFormat) Name() string                { return "dotenv" }
+func (dotenvFormat) Extension() string           { return "env" }
+func (dotenvFormat) Key(property string) string  { return strings.ToUpper(property) }
+func (dotenvFormat) InitialContents() []byte     { return nil }
+func (f dotenvFormat) StructTag(p string) string { return fmt.Sprintf("env:\"%s\"", f.Key(p)) }
+
+func (f dotenvFormat) SetProperty(filePath string, path []string, value any) error {
+	if len(path) > 1 {
+		return fmt.Errorf("dotenv files don't support sections")
+	}
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+	formatted := formatDotenvValue(value)
+	if strings.ContainsAny(formatted, " #\"'") {
+		formatted = strconv.Quote(formatted)
+	}
+	line := fmt.Sprintf("%s=%s", f.Key(path[0]), formatted)
+	if index := f.findKey(lines, path[0]); index >= 0 {
+		lines[index] = line
+	} else {
+		lines = append(lines, line)
+	}
+	return writeLines(filePath, lines)
+}
+
+// formatDotenvValue writes lists and maps the way environment variables hold them, comma separated items and
+// key:value entries, empty ones leaving the value empty
+func formatDotenvValue(value any) string {
+	switch v := value.(type) {
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, formatDotenvValue(item))
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		entries := make([]string, 0, len(v))
+		for _, key := range keys {
+			entries = append(entries, fmt.Sprintf("%s:%s", key, formatDotenvValue(v[key])))
+		}
+		return strings.Join(entries, ",")
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (f dotenvFormat) RemoveProperty(filePath string, path []string) error {
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+	if index := f.findKey(lines, path[len(path)-1]); index >= 0 {
+		lines = append(lines[:index], lines[index+1:]...)
+	}
+	return writeLines(filePath, lines)
+}
+
+func (f dotenvFormat) findKey(lines []string, property string) int {
+	for i, line := range lines {
+		key, _, found := strings.Cut(strings.TrimPrefix(strings.TrimSpace(line), "export "), "=")
+		if found && strings.TrimSpace(key) == f.Key(property) {
+			return i
+		}
+	}
+	return -1
+}
+
+// readLines returns the lines of a text file, without the trailing empty line
+func readLines(filePath string) ([]string, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	if len(contents) == 0 {
+		return nil, nil
+	}
+	return strings.Split(strings.TrimRight(string(contents), "\n"), "\n"), nil
+}
+
+func writeLines(filePath string, lines []string) error {
+	contents := strings.Join(lines, "\n")
+	if len(lines) > 0 {
+		contents += "\n"
+	}
+	return os.WriteFile(filePath, []byte(contents), 0644)
+}
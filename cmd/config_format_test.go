@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// editCase edits contents with a property set to value, or removed when remove is true
+type editCase struct {
+	name     string
+	contents string
+	path     []string
+	value    any
+	remove   bool
+	want     string
+}
+
+func runEditCases(t *testing.T, fileFormat configFormat, tests []editCase) {
+	t.Helper()
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "config."+fileFormat.Extension())
+			err := os.WriteFile(filePath, []byte(test.contents), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.remove {
+				err = fileFormat.RemoveProperty(filePath, test.path)
+			} else {
+				err = fileFormat.SetProperty(filePath, test.path, test.value)
+			}
+			if err != nil {
+				t.Fatalf("edit failed: %v", err)
+			}
+			contents, err := os.ReadFile(filePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(contents) != test.want {
+				t.Errorf("got\n%s\nwant\n%s", contents, test.want)
+			}
+		})
+	}
+}
+
+func TestTomlFormatEdit(t *testing.T) {
+	runEditCases(t, tomlFormat{}, []editCase{
+		{
+			name:  "new key in empty file",
+			path:  []string{"port"},
+			value: 8080,
+			want:  "port = 8080\n",
+		},
+		{
+			name:     "existing key",
+			contents: "port = 1\n",
+			path:     []string{"port"},
+			value:    2,
+			want:     "port = 2\n",
+		},
+		{
+			name:     "key in table with commented header",
+			contents: "[auth] # credentials\nuser = \"a\"\n",
+			path:     []string{"auth", "user"},
+			value:    "b",
+			want:     "[auth] # credentials\nuser = \"b\"\n",
+		},
+		{
+			name:     "new key at the end of its table",
+			contents: "[pool]\nsize = 1\n\n[auth]\nuser = \"a\"\n",
+			path:     []string{"pool", "max"},
+			value:    5,
+			want:     "[pool]\nsize = 1\nmax = 5\n\n[auth]\nuser = \"a\"\n",
+		},
+		{
+			name:     "new key of a nested table",
+			contents: "[db]\nhost = \"h\"\n",
+			path:     []string{"db", "pool", "size"},
+			value:    3,
+			want:     "[db]\nhost = \"h\"\npool.size = 3\n",
+		},
+		{
+			name:     "new key above the first table",
+			contents: "[pool]\nsize = 1\n",
+			path:     []string{"name"},
+			value:    "x",
+			want:     "name = \"x\"\n[pool]\nsize = 1\n",
+		},
+		{
+			name:     "empty list",
+			contents: "",
+			path:     []string{"tags"},
+			value:    []any{},
+			want:     "tags = []\n",
+		},
+		{
+			name:     "remove key of table with commented header",
+			contents: "[auth] # credentials\nuser = \"a\"\npassword = \"b\"\n",
+			path:     []string{"auth", "user"},
+			remove:   true,
+			want:     "[auth] # credentials\npassword = \"b\"\n",
+		},
+	})
+}
+
+func TestYamlFormatEdit(t *testing.T) {
+	runEditCases(t, yamlFormat{}, []editCase{
+		{
+			name:  "new key in empty file",
+			path:  []string{"port"},
+			value: 8080,
+			want:  "port: 8080\n",
+		},
+		{
+			name:     "existing key keeps its comment",
+			contents: "# server\nport: 1 # the port\n",
+			path:     []string{"port"},
+			value:    2,
+			want:     "# server\nport: 2 # the port\n",
+		},
+		{
+			name:     "new nested key",
+			contents: "db:\n    host: h\n",
+			path:     []string{"db", "pool", "size"},
+			value:    3,
+			want:     "db:\n    host: h\n    pool:\n        size: 3\n",
+		},
+		{
+			name:     "empty map",
+			contents: "",
+			path:     []string{"weights"},
+			value:    map[string]any{},
+			want:     "weights: {}\n",
+		},
+		{
+			name:     "remove key and its emptied parent",
+			contents: "db:\n  pool:\n    size: 3\nport: 1\n",
+			path:     []string{"db", "pool", "size"},
+			remove:   true,
+			want:     "port: 1\n",
+		},
+		{
+			name:     "remove first key keeps the header",
+			contents: "# header\n\nport: 1\nhost: h\n",
+			path:     []string{"port"},
+			remove:   true,
+			want:     "# header\n\nhost: h\n",
+		},
+		{
+			name:     "remove last key keeps the header",
+			contents: "# header\n\nport: 1\n",
+			path:     []string{"port"},
+			remove:   true,
+			want:     "# header\n",
+		},
+	})
+}
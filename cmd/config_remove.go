@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// removeCmd represents the remove command
+var removeCmd = &cobra.Command{
+	Use:   "remove [category_name] [property_name]",
+	Short: "Remove a property from a command set",
+	Long: `Remove a property from a command set, both from the category's config file and from
+the category's struct. Removing a section removes all of its properties.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		category := strings.ToLower(args[0])
+		propertyName := strings.ToLower(args[1])
+		section := strings.ToLower(propertySection)
+		if exists, err := categoryExists(category); !exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("the category %v doesn't exist", category)
+		}
+		if exists, err := propertyExistsInCategory(category, section, propertyName); !exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("the property %v in category %v doesn't exist", propertyName, category)
+		}
+		return removePropertyFromCategory(category, section, propertyName)
+	},
+}
+
+func removePropertyFromCategory(category string, section string, name string) error {
+	fileFormat, found := findCategoryFormat(category)
+	if !found {
+		return fmt.Errorf("config file of category %s not found", category)
+	}
+	err := fileFormat.RemoveProperty(configFilePath(category, fileFormat), propertyPath(section, name))
+	if err != nil {
+		return err
+	}
+
+	source, err := parseConfigSource(category)
+	if err != nil {
+		return err
+	}
+	structType, err := source.propertyStruct(category, section, "", false)
+	if err != nil {
+		return err
+	}
+	removeField(structType, name)
+	if section != "" && len(structType.Fields.List) == 0 {
+		removeField(source.categoryStruct(category), section)
+	}
+	err = source.write()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(categoryFlagsPath(category)); err == nil {
+		err = generateCategoryFlags(category)
+		if err != nil {
+			return err
+		}
+	}
+	return generateCategoryTests(category)
+}
+
+func init() {
+	configCmd.AddCommand(removeCmd)
+	removeCmd.Flags().StringVarP(&propertySection, "section", "s", "", "Section of the category the property is nested under")
+}
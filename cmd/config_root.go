@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"strconv"
+	"template/config"
+)
+
+const (
+	rootConfigSourceName = "config.go"
+	rootConfigStructName = "Config"
+	rootLoadFunc         = "load"
+)
+
+// reservedCategoryNames would collide with the files generated next to the categories
+var reservedCategoryNames = map[string]bool{"config": true, "validate": true, "env_file": true, "source": true}
+
+// reservedCategorySuffixes would make the source of a category the file generated for another category,
+// _test making it a test file as well
+var reservedCategorySuffixes = []string{"_loader", "_flags", "_test"}
+
+const rootConfigSourceContents = `package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Config aggregates the configuration of every category. It's maintained by the generator.
+type Config struct {
+}
+
+// Option customizes Load
+type Option func(*loadOptions)
+
+type loadOptions struct {
+	skip map[string]bool
+}
+
+// Skip excludes the categories from loading, their fields stay nil
+func Skip(categories ...string) Option {
+	return func(o *loadOptions) {
+		for _, category := range categories {
+			o.skip[category] = true
+		}
+	}
+}
+
+// Load loads and validates every category. Failures of all categories are reported together in a LoadError.
+func Load(opts ...Option) (*Config, error) {
+	options := &loadOptions{skip: map[string]bool{}}
+	for _, opt := range opts {
+		opt(options)
+	}
+	config := &Config{}
+	loadErr := config.load(options)
+	if len(loadErr.Errors) > 0 {
+		return nil, loadErr
+	}
+	return config, nil
+}
+
+// load loads the categories not skipped by the options. It's maintained by the generator.
+func (c *Config) load(options *loadOptions) *LoadError {
+	loadErr := &LoadError{}
+	return loadErr
+}
+
+// CategoryError is the failure of loading a single category
+type CategoryError struct {
+	Category string
+	Err      error
+}
+
+func (e CategoryError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Category, e.Err)
+}
+
+func (e CategoryError) Unwrap() error {
+	return e.Err
+}
+
+// LoadError aggregates the failures of all categories
+type LoadError struct {
+	Errors []CategoryError
+}
+
+func (e *LoadError) add(category string, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, CategoryError{Category: category, Err: err})
+	}
+}
+
+func (e *LoadError) Error() string {
+	messages := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		messages = append(messages, err.Error())
+	}
+	return "failed to load configuration: " + strings.Join(messages, "; ")
+}
+
+func (e *LoadError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+`
+
+func rootConfigSourcePath() string {
+	return fmt.Sprintf("%s/%s", config.ConfigSourceDirectory, rootConfigSourceName)
+}
+
+// ensureRootConfig writes the root config unless it exists. Projects generated before it existed get
+// all of their categories added.
+func ensureRootConfig() error {
+	if _, err := os.Stat(rootConfigSourcePath()); err == nil {
+		return nil
+	}
+	err := os.WriteFile(rootConfigSourcePath(), []byte(rootConfigSourceContents), 0644)
+	if err != nil {
+		return err
+	}
+	categories, err := listCategories()
+	if err != nil {
+		return err
+	}
+	for _, category := range categories {
+		err = addCategoryToRoot(category)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseRootConfig() (*configSource, *ast.StructType, *ast.BlockStmt, error) {
+	path := rootConfigSourcePath()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	source := &configSource{path: path, fset: fset, file: file}
+	var structType *ast.StructType
+	var loadBody *ast.BlockStmt
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok && typeSpec.Name.Name == rootConfigStructName {
+					structType, _ = typeSpec.Type.(*ast.StructType)
+				}
+			}
+		case *ast.FuncDecl:
+			if d.Name.Name == rootLoadFunc && d.Recv != nil && d.Body != nil {
+				loadBody = d.Body
+			}
+		}
+	}
+	if structType == nil || loadBody == nil || len(loadBody.List) == 0 {
+		return nil, nil, nil, fmt.Errorf("%s doesn't declare the %s struct and its %s method", path, rootConfigStructName, rootLoadFunc)
+	}
+	return source, structType, loadBody, nil
+}
+
+// addCategoryToRoot adds the category's field to the root config and loads it in the root's load method
+func addCategoryToRoot(category string) error {
+	source, structType, loadBody, err := parseRootConfig()
+	if err != nil {
+		return err
+	}
+	field := fieldName(category)
+	if findField(structType, field) != nil {
+		return nil
+	}
+	rootField := &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(field)},
+		Type:  &ast.StarExpr{X: ast.NewIdent(categoryStructName(category))},
+	}
+	placeAt(rootField, structType.Fields.Closing)
+	structType.Fields.List = append(structType.Fields.List, rootField)
+
+	// if !options.skip["category"] { var err error; c.Category, err = NewCategoryConfig(); loadErr.add("category", err) }
+	categoryLiteral := &ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(category)}
+	loadStatement := &ast.IfStmt{
+		Cond: &ast.UnaryExpr{
+			Op: token.NOT,
+			X: &ast.IndexExpr{
+				X:     &ast.SelectorExpr{X: ast.NewIdent("options"), Sel: ast.NewIdent("skip")},
+				Index: categoryLiteral,
+			},
+		},
+		Body: &ast.BlockStmt{List: []ast.Stmt{
+			&ast.DeclStmt{Decl: &ast.GenDecl{
+				Tok:   token.VAR,
+				Specs: []ast.Spec{&ast.ValueSpec{Names: []*ast.Ident{ast.NewIdent("err")}, Type: ast.NewIdent("error")}},
+			}},
+			&ast.AssignStmt{
+				Lhs: []ast.Expr{
+					&ast.SelectorExpr{X: ast.NewIdent("c"), Sel: ast.NewIdent(field)},
+					ast.NewIdent("err"),
+				},
+				Tok: token.ASSIGN,
+				Rhs: []ast.Expr{&ast.CallExpr{Fun: ast.NewIdent(fmt.Sprintf("New%s", categoryStructName(category)))}},
+			},
+			&ast.ExprStmt{X: &ast.CallExpr{
+				Fun:  &ast.SelectorExpr{X: ast.NewIdent("loadErr"), Sel: ast.NewIdent("add")},
+				Args: []ast.Expr{categoryLiteral, ast.NewIdent("err")},
+			}},
+		}},
+	}
+	last := len(loadBody.List) - 1
+	placeAt(loadStatement, loadBody.List[last].Pos())
+	loadBody.List = append(loadBody.List[:last], loadStatement, loadBody.List[last])
+	return source.write()
+}
+
+// removeCategoryFromRoot removes what addCategoryToRoot added
+func removeCategoryFromRoot(category string) error {
+	source, structType, loadBody, err := parseRootConfig()
+	if err != nil {
+		return err
+	}
+	removeField(structType, fieldName(category))
+	for i, statement := range loadBody.List {
+		ifStatement, ok := statement.(*ast.IfStmt)
+		if !ok {
+			continue
+		}
+		condition, ok := ifStatement.Cond.(*ast.UnaryExpr)
+		if !ok {
+			continue
+		}
+		index, ok := condition.X.(*ast.IndexExpr)
+		if !ok {
+			continue
+		}
+		if literal, ok := index.Index.(*ast.BasicLit); ok && literal.Value == strconv.Quote(category) {
+			loadBody.List = append(loadBody.List[:i], loadBody.List[i+1:]...)
+			break
+		}
+	}
+	return source.write()
+}
@@ -0,0 +1,359 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"template/config"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	jsonSchemaDialect    = "https://json-schema.org/draft/2020-12/schema"
+	yamlSchemaHeader     = "# yaml-language-server: $schema="
+	durationPattern      = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+	defaultSchemaDirName = "config/schema"
+)
+
+var schemaOutputDirectory string
+var schemaYamlHeader bool
+
+// schemaCmd represents the schema command
+var schemaCmd = &cobra.Command{
+	Use:   "schema [category_name]",
+	Short: "Export JSON Schema of the config categories",
+	Long: `Export JSON Schema of the config categories. The schema is built from the category's
+struct: field types, nested structs and the default, validate and secret tags. Without a category
+name the schema of every category is exported.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		categories, err := listCategories()
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			category := strings.ToLower(args[0])
+			if exists, err := categoryExists(category); !exists || err != nil {
+				if err != nil {
+					return err
+				}
+				return fmt.Errorf("the category %v doesn't exist", category)
+			}
+			categories = []string{category}
+		}
+		err = os.MkdirAll(schemaOutputDirectory, os.ModePerm)
+		if err != nil {
+			return err
+		}
+		for _, category := range categories {
+			schemaPath, err := exportCategorySchema(category, schemaOutputDirectory)
+			if err != nil {
+				return fmt.Errorf("failed to export schema of category %s: %w", category, err)
+			}
+			if schemaYamlHeader {
+				err = addYamlSchemaHeader(category, schemaPath)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+}
+
+// jsonSchema is the subset of JSON Schema needed to describe config structs
+type jsonSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 string                 `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	AdditionalProperties *jsonSchema            `json:"additionalProperties,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Default              any                    `json:"default,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	WriteOnly            bool                   `json:"writeOnly,omitempty"`
+}
+
+func exportCategorySchema(category string, outputDirectory string) (string, error) {
+	source, err := parseConfigSource(category)
+	if err != nil {
+		return "", err
+	}
+	structType := source.categoryStruct(category)
+	if structType == nil {
+		return "", fmt.Errorf("struct %s not found", categoryStructName(category))
+	}
+	types, err := packageTypes()
+	if err != nil {
+		return "", err
+	}
+	schema, err := structSchema(structType, types)
+	if err != nil {
+		return "", err
+	}
+	schema.Schema = jsonSchemaDialect
+	schema.Title = categoryStructName(category)
+	contents, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	schemaPath := filepath.Join(outputDirectory, category+".schema.json")
+	return schemaPath, os.WriteFile(schemaPath, append(contents, '\n'), 0644)
+}
+
+// packageTypes collects the type declarations of the config package, so named types used by fields can be described
+func packageTypes() (map[string]ast.Expr, error) {
+	types := map[string]ast.Expr{}
+	fset := token.NewFileSet()
+	files, err := filepath.Glob(filepath.Join(config.ConfigSourceDirectory, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range files {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, decl := range file.Decls {
+			declaration, ok := decl.(*ast.GenDecl)
+			if !ok || declaration.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range declaration.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					types[typeSpec.Name.Name] = typeSpec.Type
+				}
+			}
+		}
+	}
+	return types, nil
+}
+
+func structSchema(structType *ast.StructType, types map[string]ast.Expr) (*jsonSchema, error) {
+	schema := &jsonSchema{Type: "object", Properties: map[string]*jsonSchema{}}
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			property, err := typeSchema(field.Type, types)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			property.Description = fieldDescription(field)
+			required, err := applyFieldTags(property, tag)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", name.Name, err)
+			}
+			key := propertyKey(name.Name, tag)
+			schema.Properties[key] = property
+			if required {
+				schema.Required = append(schema.Required, key)
+			}
+		}
+	}
+	return schema, nil
+}
+
+func typeSchema(expr ast.Expr, types map[string]ast.Expr) (*jsonSchema, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return &jsonSchema{Type: "string"}, nil
+		case "bool":
+			return &jsonSchema{Type: "boolean"}, nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return &jsonSchema{Type: "integer"}, nil
+		case "float32", "float64":
+			return &jsonSchema{Type: "number"}, nil
+		case "any":
+			return &jsonSchema{}, nil
+		}
+		if named, ok := types[t.Name]; ok {
+			delete(types, t.Name) // guards against recursive types
+			defer func() { types[t.Name] = named }()
+			return typeSchema(named, types)
+		}
+		return &jsonSchema{}, nil
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && pkg.Name == "time" {
+			switch t.Sel.Name {
+			case "Duration":
+				return &jsonSchema{Type: "string", Pattern: durationPattern}, nil
+			case "Time":
+				return &jsonSchema{Type: "string", Format: "date-time"}, nil
+			}
+		}
+		return &jsonSchema{}, nil
+	case *ast.StarExpr:
+		return typeSchema(t.X, types)
+	case *ast.ArrayType:
+		items, err := typeSchema(t.Elt, types)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "array", Items: items}, nil
+	case *ast.MapType:
+		values, err := typeSchema(t.Value, types)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonSchema{Type: "object", AdditionalProperties: values}, nil
+	case *ast.StructType:
+		return structSchema(t, types)
+	case *ast.InterfaceType:
+		return &jsonSchema{}, nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", expr)
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+// propertyKey returns the key of the field in the config file, taken from the first format tag present
+func propertyKey(name string, tag reflect.StructTag) string {
+	for _, key := range []string{"yaml", "json", "toml", "env", "koanf", "mapstructure"} {
+		value, _, _ := strings.Cut(tag.Get(key), ",")
+		if value != "" && value != "-" {
+			return value
+		}
+	}
+	return strings.ToLower(name)
+}
+
+// applyFieldTags describes the default, validate and secret tags in the schema and reports whether the field is required
+func applyFieldTags(schema *jsonSchema, tag reflect.StructTag) (bool, error) {
+	if value, ok := tag.Lookup("default"); ok {
+		var parsed any
+		err := yaml.Unmarshal([]byte(value), &parsed)
+		if err != nil {
+			return false, fmt.Errorf("invalid default value %q: %w", value, err)
+		}
+		if schema.Type == "string" {
+			parsed = value
+		}
+		schema.Default = parsed
+	}
+	if tag.Get("secret") == "true" {
+		schema.WriteOnly = true
+	}
+	var required bool
+	for name, argument := range validationRules(tag) {
+		switch name {
+		case "required":
+			required = true
+		case "min", "max":
+			limit, err := strconv.ParseFloat(argument, 64)
+			if err != nil {
+				return false, fmt.Errorf("invalid %s rule %q", name, argument)
+			}
+			applyLimit(schema, name, limit)
+		case "oneof":
+			for _, option := range strings.Fields(argument) {
+				var parsed any = option
+				if schema.Type != "string" {
+					_ = yaml.Unmarshal([]byte(option), &parsed)
+				}
+				schema.Enum = append(schema.Enum, parsed)
+			}
+		}
+	}
+	return required, nil
+}
+
+// applyLimit maps min and max rules to the keyword matching the type, the way validator libraries interpret them
+func applyLimit(schema *jsonSchema, rule string, limit float64) {
+	count := int(limit)
+	switch schema.Type {
+	case "string":
+		if rule == "min" {
+			schema.MinLength = &count
+		} else {
+			schema.MaxLength = &count
+		}
+	case "array":
+		if rule == "min" {
+			schema.MinItems = &count
+		} else {
+			schema.MaxItems = &count
+		}
+	default:
+		if rule == "min" {
+			schema.Minimum = &limit
+		} else {
+			schema.Maximum = &limit
+		}
+	}
+}
+
+// addYamlSchemaHeader points the yaml language server at the schema, replacing a previous header
+func addYamlSchemaHeader(category string, schemaPath string) error {
+	fileFormat, found := findCategoryFormat(category)
+	if !found || fileFormat.Name() != "yaml" {
+		return nil
+	}
+	filePath := configFilePath(category, fileFormat)
+	// the schema directory given with --out is absolute
+	configDirectory, err := filepath.Abs(filepath.Dir(filePath))
+	if err != nil {
+		return err
+	}
+	schemaPath, err = filepath.Abs(schemaPath)
+	if err != nil {
+		return err
+	}
+	relativePath, err := filepath.Rel(configDirectory, schemaPath)
+	if err != nil {
+		return err
+	}
+	lines, err := readLines(filePath)
+	if err != nil {
+		return err
+	}
+	header := yamlSchemaHeader + filepath.ToSlash(relativePath)
+	if len(lines) > 0 && strings.HasPrefix(lines[0], yamlSchemaHeader) {
+		lines[0] = header
+	} else {
+		lines = append([]string{header}, lines...)
+	}
+	return writeLines(filePath, lines)
+}
+
+func init() {
+	configCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringVarP(&schemaOutputDirectory, "out", "o", defaultSchemaDirName, "Directory the schema files are written to")
+	schemaCmd.MarkFlagDirname("out")
+	schemaCmd.Flags().BoolVar(&schemaYamlHeader, "yaml-header", false, "Add a yaml-language-server header pointing at the schema to each yaml config file")
+}
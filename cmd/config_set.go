@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// setCmd represents the set command
+var setCmd = &cobra.Command{
+	Use:   "set [category_name] [key] [value]",
+	Short: "Change the value of a key in the category's config file",
+	Long: `Change the value of a key in the category's config file. Nested keys are addressed
+with dots, e.g. server.port. The value is interpreted the way a yaml scalar would be.`,
+	Args: cobra.ExactArgs(3),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		category := strings.ToLower(args[0])
+		if exists, err := categoryExists(category); !exists || err != nil {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("the category %v doesn't exist", category)
+		}
+		fileFormat, _ := findCategoryFormat(category)
+		var value any
+		err := yaml.Unmarshal([]byte(args[2]), &value)
+		if err != nil {
+			return fmt.Errorf("failed to parse value %q: %w", args[2], err)
+		}
+		return fileFormat.SetProperty(configFilePath(category, fileFormat), strings.Split(args[1], "."), value)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(setCmd)
+}
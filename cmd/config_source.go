@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"template/config"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/tools/go/ast/astutil"
+)
+
+// configSource is a parsed source file of a config category
+type configSource struct {
+	path string
+	fset *token.FileSet
+	file *ast.File
+	// the printer can't place comments of nodes created without positions, so doc comments
+	// of new fields are inserted into the printed code
+	docs []fieldDoc
+}
+
+type fieldDoc struct {
+	category string
+	section  string
+	property string
+	text     string
+}
+
+func categoryStructName(category string) string {
+	return cases.Title(language.Und).String(category) + "Config"
+}
+
+// fieldName upper cases the first letter of the property, keeping the case of the rest
+func fieldName(property string) string {
+	first, size := utf8.DecodeRuneInString(property)
+	return string(unicode.ToUpper(first)) + property[size:]
+}
+
+// typeImports are the packages the types of properties and fields can refer to, by their name
+var typeImports = map[string]string{"time": "time"}
+
+// checkTypeName fails unless the type is built from predeclared types and the types of typeImports, like
+// []string, map[string]int or time.Duration
+func checkTypeName(typeName string) error {
+	expr, err := parser.ParseExpr(typeName)
+	if err != nil {
+		return fmt.Errorf("invalid type %q: %w", typeName, err)
+	}
+	var check func(expr ast.Expr) error
+	check = func(expr ast.Expr) error {
+		switch expr := expr.(type) {
+		case *ast.Ident:
+			if _, ok := types.Universe.Lookup(expr.Name).(*types.TypeName); ok {
+				return nil
+			}
+		case *ast.SelectorExpr:
+			if pkg, ok := expr.X.(*ast.Ident); ok && typeImports[pkg.Name] != "" && expr.Sel.IsExported() {
+				return nil
+			}
+		case *ast.StarExpr:
+			return check(expr.X)
+		case *ast.ArrayType:
+			return check(expr.Elt)
+		case *ast.MapType:
+			if err := check(expr.Key); err != nil {
+				return err
+			}
+			return check(expr.Value)
+		}
+		var packages []string
+		for name := range typeImports {
+			packages = append(packages, name)
+		}
+		sort.Strings(packages)
+		return fmt.Errorf("unsupported type %q, %s isn't a predeclared type or a type of %s", typeName, types.ExprString(expr), strings.Join(packages, ", "))
+	}
+	return check(expr)
+}
+
+// addTypeImports imports the packages the type refers to into the file
+func addTypeImports(fset *token.FileSet, file *ast.File, typeName string) {
+	expr, err := parser.ParseExpr(typeName)
+	if err != nil {
+		return
+	}
+	ast.Inspect(expr, func(node ast.Node) bool {
+		if selector, ok := node.(*ast.SelectorExpr); ok {
+			if pkg, ok := selector.X.(*ast.Ident); ok && typeImports[pkg.Name] != "" {
+				astutil.AddImport(fset, file, typeImports[pkg.Name])
+			}
+		}
+		return true
+	})
+}
+
+// listCategories returns the names of all categories of the project, sorted
+func listCategories() ([]string, error) {
+	entries, err := os.ReadDir(config.ConfigSourceDirectory)
+	if err != nil {
+		return nil, err
+	}
+	var categories []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		category := strings.TrimSuffix(name, ".go")
+		exists, err := categoryExists(category)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+func parseConfigSource(category string) (*configSource, error) {
+	return parseGoSource(configSourcePath(category))
+}
+
+// parseGoSource parses a source file of the project to edit it like the sources of the config categories
+func parseGoSource(path string) (*configSource, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, nil, parser.AllErrors|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	return &configSource{path: path, fset: fset, file: file}, nil
+}
+
+// categoryStruct returns the struct of the category's config, or nil if the file doesn't declare it
+func (s *configSource) categoryStruct(category string) *ast.StructType {
+	structName := categoryStructName(category)
+	for _, decl := range s.file.Decls {
+		declaration, ok := decl.(*ast.GenDecl)
+		if !ok || declaration.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range declaration.Specs {
+			typeSpec, ok := spec.(*ast.TypeSpec)
+			if !ok || typeSpec.Name.Name != structName {
+				continue
+			}
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				return structType
+			}
+		}
+	}
+	return nil
+}
+
+// constructor returns the New function of the category's config, or nil if the file doesn't declare it
+func (s *configSource) constructor(category string) *ast.FuncDecl {
+	return s.function(fmt.Sprintf("New%s", categoryStructName(category)))
+}
+
+// function returns the function declared with the name, nil if there's none
+func (s *configSource) function(name string) *ast.FuncDecl {
+	for _, decl := range s.file.Decls {
+		if function, ok := decl.(*ast.FuncDecl); ok && function.Recv == nil && function.Name.Name == name {
+			return function
+		}
+	}
+	return nil
+}
+
+// propertyStruct returns the struct holding the properties of a section. An empty section means the
+// category struct itself. With create, a missing section gets a nested struct field.
+func (s *configSource) propertyStruct(category string, section string, tag string, create bool) (*ast.StructType, error) {
+	structType := s.categoryStruct(category)
+	if structType == nil {
+		return nil, fmt.Errorf("struct %s not found in %s", categoryStructName(category), s.path)
+	}
+	if section == "" {
+		return structType, nil
+	}
+	field := findField(structType, section)
+	if field != nil {
+		nested, ok := field.Type.(*ast.StructType)
+		if !ok {
+			return nil, fmt.Errorf("property %s of category %s is not a section", section, category)
+		}
+		return nested, nil
+	}
+	if !create {
+		return nil, nil
+	}
+	// positioning the new struct at the end of the parent keeps the printer from moving comments into it
+	nested := &ast.StructType{
+		Struct: structType.Fields.Closing - 1,
+		Fields: &ast.FieldList{Opening: structType.Fields.Closing - 1, Closing: structType.Fields.Closing - 1},
+	}
+	structType.Fields.List = append(structType.Fields.List, &ast.Field{
+		Names: []*ast.Ident{ast.NewIdent(fieldName(section))},
+		Type:  nested,
+		Tag:   &ast.BasicLit{Kind: token.STRING, Value: tag},
+	})
+	return nested, nil
+}
+
+// placeAt positions every node of the tree at pos. The printer places comments by comparing positions,
+// so nodes created without them can end up with comments of their neighbours.
+func placeAt(node ast.Node, pos token.Pos) {
+	posType := reflect.TypeOf(token.NoPos)
+	ast.Inspect(node, func(n ast.Node) bool {
+		if n == nil {
+			return false
+		}
+		value := reflect.ValueOf(n)
+		if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+			return true
+		}
+		value = value.Elem()
+		for i := 0; i < value.NumField(); i++ {
+			// a valid Ellipsis turns a call into a variadic one and valid parentheses group a declaration
+			name := value.Type().Field(i).Name
+			if _, isDeclaration := n.(*ast.GenDecl); name == "Ellipsis" || isDeclaration && (name == "Lparen" || name == "Rparen") {
+				continue
+			}
+			if field := value.Field(i); field.Type() == posType && field.CanSet() {
+				field.SetInt(int64(pos))
+			}
+		}
+		return true
+	})
+}
+
+// findField looks the field up by its property name, ignoring the case like the config libraries do
+func findField(structType *ast.StructType, property string) *ast.Field {
+	for _, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if strings.EqualFold(name.Name, property) {
+				return field
+			}
+		}
+	}
+	return nil
+}
+
+func removeField(structType *ast.StructType, property string) bool {
+	for i, field := range structType.Fields.List {
+		for _, name := range field.Names {
+			if strings.EqualFold(name.Name, property) {
+				structType.Fields.List = append(structType.Fields.List[:i], structType.Fields.List[i+1:]...)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setFieldDoc attaches a doc comment to the field of the property when the source is written
+func (s *configSource) setFieldDoc(category string, section string, property string, text string) {
+	s.docs = append(s.docs, fieldDoc{category: category, section: section, property: property, text: text})
+}
+
+func (s *configSource) write() error {
+	var buf bytes.Buffer
+	err := printer.Fprint(&buf, s.fset, s.file)
+	if err != nil {
+		return fmt.Errorf("failed to print ast code: %w", err)
+	}
+	formattedCode, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	for _, doc := range s.docs {
+		formattedCode, err = insertFieldDoc(formattedCode, doc)
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(s.path, formattedCode, 0644)
+}
+
+func insertFieldDoc(code []byte, doc fieldDoc) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", code, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	printed := &configSource{fset: fset, file: file}
+	structType, err := printed.propertyStruct(doc.category, doc.section, "", false)
+	if err != nil || structType == nil {
+		return nil, fmt.Errorf("failed to find struct of property %s: %v", doc.property, err)
+	}
+	field := findField(structType, doc.property)
+	if field == nil {
+		return nil, fmt.Errorf("failed to find field of property %s", doc.property)
+	}
+	if field.Doc != nil {
+		return code, nil
+	}
+	offset := fset.Position(field.Pos()).Offset
+	lineStart := bytes.LastIndexByte(code[:offset], '\n') + 1
+	var comment bytes.Buffer
+	for _, line := range strings.Split(doc.text, "\n") {
+		comment.Write(code[lineStart:offset])
+		comment.WriteString(strings.TrimSpace("// " + line))
+		comment.WriteByte('\n')
+	}
+	code = append(code[:lineStart], append(comment.Bytes(), code[lineStart:]...)...)
+	return format.Source(code)
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"sort"
+	"strings"
+	"template/config"
+)
+
+const sourcesFileName = "source.go"
+
+// sourcesContents declares the Source interface the categories created with --source load from, its
+// implementations and the registry letting the application or its tests replace a category's source
+const sourcesContents = `package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/spf13/viper"
+)
+
+// Source provides the properties of a category as nested maps, keyed the way the category's config file is
+type Source interface {
+	Load(category string) (map[string]any, error)
+}
+
+var (
+	sourcesMutex sync.RWMutex
+	sources      = map[string]Source{}
+)
+
+// SetSource replaces the source the category is loaded from. A nil source restores the one the category
+// was generated with.
+func SetSource(category string, source Source) {
+	sourcesMutex.Lock()
+	defer sourcesMutex.Unlock()
+	if source == nil {
+		delete(sources, category)
+		return
+	}
+	sources[category] = source
+}
+
+// sourceOf returns the source set for the category with SetSource, or the fallback
+func sourceOf(category string, fallback Source) Source {
+	sourcesMutex.RLock()
+	defer sourcesMutex.RUnlock()
+	if source, ok := sources[category]; ok {
+		return source
+	}
+	return fallback
+}
+
+// FileSource reads the category's file from the directory, in any format viper reads
+type FileSource struct {
+	Directory string
+}
+
+func (s FileSource) Load(category string) (map[string]any, error) {
+	for _, extension := range []string{"yaml", "yml", "json", "toml", "env"} {
+		path := filepath.Join(s.Directory, category+"."+extension)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(path)
+		err := v.ReadInConfig()
+		if err != nil {
+			return nil, err
+		}
+		return v.AllSettings(), nil
+	}
+	return nil, fmt.Errorf("no config file of category %s in %s", category, s.Directory)
+}
+
+// EnvSource reads the CATEGORY_KEY environment variables of the category. Double underscores separate
+// sections, DATABASE_SERVER__PORT sets port in the server section of the database category.
+type EnvSource struct{}
+
+func (EnvSource) Load(category string) (map[string]any, error) {
+	prefix := strings.ToUpper(category) + "_"
+	values := map[string]any{}
+	for _, variable := range os.Environ() {
+		key, value, _ := strings.Cut(variable, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		path := strings.Split(strings.ToLower(strings.TrimPrefix(key, prefix)), "__")
+		values[strings.Join(path, ".")] = value
+	}
+	return nest(values), nil
+}
+
+// HTTPSource reads the category from a key/value HTTP endpoint serving it as a JSON object at URL/category.
+// Keys may be nested objects or dotted paths. Without URL, the CONFIG_SOURCE_URL environment variable is used.
+type HTTPSource struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s HTTPSource) Load(category string) (map[string]any, error) {
+	url := s.URL
+	if url == "" {
+		url = os.Getenv("CONFIG_SOURCE_URL")
+	}
+	if url == "" {
+		return nil, fmt.Errorf("the URL of the config endpoint isn't set, set CONFIG_SOURCE_URL")
+	}
+	var values map[string]any
+	err := getJSON(s.Client, strings.TrimSuffix(url, "/")+"/"+category, nil, &values)
+	if err != nil {
+		return nil, err
+	}
+	return nest(values), nil
+}
+
+// VaultSource reads the category from the secret Mount/category of a Vault KV version 2 secrets engine.
+// Without Address and Token, VAULT_ADDR and VAULT_TOKEN are used. Mount defaults to secret.
+type VaultSource struct {
+	Address string
+	Token   string
+	Mount   string
+	Client  *http.Client
+}
+
+func (s VaultSource) Load(category string) (map[string]any, error) {
+	address, token, mount := s.Address, s.Token, s.Mount
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if token == "" {
+		token = os.Getenv("VAULT_TOKEN")
+	}
+	if mount == "" {
+		mount = "secret"
+	}
+	if address == "" {
+		return nil, fmt.Errorf("the address of Vault isn't set, set VAULT_ADDR")
+	}
+	var secret struct {
+		Data struct {
+			Data map[string]any ` + "`json:\"data\"`" + `
+		} ` + "`json:\"data\"`" + `
+	}
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimSuffix(address, "/"), mount, category)
+	err := getJSON(s.Client, url, map[string]string{"X-Vault-Token": token}, &secret)
+	if err != nil {
+		return nil, err
+	}
+	return nest(secret.Data.Data), nil
+}
+
+// MemorySource serves the properties of each category from memory. It stands in for the remote sources in tests.
+type MemorySource map[string]map[string]any
+
+func (s MemorySource) Load(category string) (map[string]any, error) {
+	values, ok := s[category]
+	if !ok {
+		return nil, fmt.Errorf("no config of category %s", category)
+	}
+	return nest(values), nil
+}
+
+func getJSON(client *http.Client, url string, headers map[string]string, target any) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, response.Status)
+	}
+	return json.NewDecoder(response.Body).Decode(target)
+}
+
+// nest turns dotted keys into nested maps, the way config files nest sections
+func nest(values map[string]any) map[string]any {
+	nested := map[string]any{}
+	for key, value := range values {
+		if inner, ok := value.(map[string]any); ok {
+			value = nest(inner)
+		}
+		path := strings.Split(key, ".")
+		current := nested
+		for _, section := range path[:len(path)-1] {
+			next, ok := current[section].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				current[section] = next
+			}
+			current = next
+		}
+		current[path[len(path)-1]] = value
+	}
+	return nested
+}
+`
+
+// configSourceKinds maps the kinds of sources to the type of the source categories fall back to
+var configSourceKinds = map[string]string{
+	"file":  "FileSource",
+	"env":   "EnvSource",
+	"http":  "HTTPSource",
+	"vault": "VaultSource",
+}
+
+func configSourceKindNames() []string {
+	names := make([]string, 0, len(configSourceKinds))
+	for name := range configSourceKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sourceBackend loads a category with viper from a Source, instead of reading the category's file directly
+type sourceBackend struct {
+	viperBackend
+	kind string
+}
+
+func newSourceBackend(backend configBackend, kind string) (configBackend, error) {
+	if _, ok := configSourceKinds[kind]; !ok {
+		return nil, fmt.Errorf("unsupported source %q, expected one of: %s", kind, strings.Join(configSourceKindNames(), ", "))
+	}
+	if backend.Name() != "viper" {
+		return nil, fmt.Errorf("--source needs the viper config library, the project uses %s", backend.Name())
+	}
+	return sourceBackend{kind: kind}, nil
+}
+
+// fallback returns the literal of the source the category is loaded from unless SetSource replaces it
+func (b sourceBackend) fallback() ast.Expr {
+	literal := &ast.CompositeLit{Type: ast.NewIdent(configSourceKinds[b.kind])}
+	if b.kind == "file" {
+		literal.Elts = []ast.Expr{&ast.KeyValueExpr{Key: ast.NewIdent("Directory"), Value: stringLiteral(config.ConfigFileDirectory)}}
+	}
+	return literal
+}
+
+func (b sourceBackend) LoadStatements(category string, fileFormat configFormat) []ast.Stmt {
+	instanceCall := func(method string, args ...ast.Expr) *ast.CallExpr {
+		return selectorCall("v", method, args...)
+	}
+	return []ast.Stmt{
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("v")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{selectorCall("viper", "New")},
+		},
+		&ast.ExprStmt{X: instanceCall("SetEnvPrefix", stringLiteral(category))},
+		&ast.ExprStmt{X: instanceCall("SetEnvKeyReplacer", selectorCall("strings", "NewReplacer", stringLiteral("."), stringLiteral("_")))},
+		&ast.ExprStmt{X: instanceCall("AutomaticEnv")},
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("values"), ast.NewIdent("err")},
+			Tok: token.DEFINE,
+			Rhs: []ast.Expr{&ast.CallExpr{
+				Fun: &ast.SelectorExpr{
+					X:   &ast.CallExpr{Fun: ast.NewIdent("sourceOf"), Args: []ast.Expr{stringLiteral(category), b.fallback()}},
+					Sel: ast.NewIdent("Load"),
+				},
+				Args: []ast.Expr{stringLiteral(category)},
+			}},
+		},
+		errorReturnStmt(),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{instanceCall("MergeConfigMap", ast.NewIdent("values"))},
+		},
+		errorReturnStmt(),
+		newConfigStmt(category),
+		&ast.AssignStmt{
+			Lhs: []ast.Expr{ast.NewIdent("err")},
+			Tok: token.ASSIGN,
+			Rhs: []ast.Expr{instanceCall("Unmarshal", ast.NewIdent("config"))},
+		},
+		errorReturnStmt(),
+	}
+}
+
+// Declarations returns nothing, the constructor setting up its viper instance itself
+func (sourceBackend) Declarations(string, configFormat) []ast.Decl { return nil }
+
+func (sourceBackend) Helpers() map[string]string {
+	return map[string]string{sourcesFileName: sourcesContents}
+}
+
+// loadsFromSource reports whether the category's constructor loads it from a Source
+func loadsFromSource(source *configSource, category string) bool {
+	constructor := source.constructor(category)
+	if constructor == nil {
+		return false
+	}
+	var found bool
+	ast.Inspect(constructor.Body, func(node ast.Node) bool {
+		if ident, ok := node.(*ast.Ident); ok && ident.Name == "sourceOf" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
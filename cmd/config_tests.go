@@ -0,0 +1,508 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/types"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"template/config"
+	"text/template"
+)
+
+var categoryTestTemplate = template.Must(template.New("test").Parse(`package config
+
+import (
+	"os"
+	"path/filepath"
+	{{- if .Reflect}}
+	"reflect"
+	{{- end}}
+	"strings"
+	"testing"
+)
+
+// {{.Contents}} sets every property of {{.Struct}}. It's maintained by the generator.
+const {{.Contents}} = {{.Valid}}
+
+// write{{.Name}}TestConfig writes the contents as {{.File}} of a temporary working directory
+func write{{.Name}}TestConfig(t *testing.T, contents string) {
+	t.Helper()
+	directory := t.TempDir()
+	path := filepath.Join(directory, {{printf "%q" .File}})
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(contents), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(workingDirectory)
+	})
+	{{- if .Source}}
+	SetSource({{printf "%q" .Category}}, FileSource{Directory: filepath.Dir({{printf "%q" .File}})})
+	t.Cleanup(func() {
+		SetSource({{printf "%q" .Category}}, nil)
+	})
+	{{- end}}
+}
+
+func TestNew{{.Struct}}(t *testing.T) {
+	write{{.Name}}TestConfig(t, {{.Contents}})
+	{{if .Assertions}}config{{else}}_{{end}}, err := New{{.Struct}}()
+	if err != nil {
+		t.Fatalf("New{{.Struct}}() failed: %v", err)
+	}
+	{{- range .Assertions}}
+	{{- if .Expected}}
+	if config.{{.Field}} != {{.Expected}} {
+		t.Errorf("{{.Field}} = %v, want %v", config.{{.Field}}, {{.Expected}})
+	}
+	{{- else}}
+	if reflect.ValueOf(config.{{.Field}}).IsZero() {
+		t.Errorf("{{.Field}} is not populated")
+	}
+	{{- end}}
+	{{- end}}
+}
+
+
+// TestNew{{.Struct}}ProjectFile loads {{.File}} of the project, the file the applications read
+func TestNew{{.Struct}}ProjectFile(t *testing.T) {
+	contents, err := os.ReadFile({{printf "%q" .ProjectFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	write{{.Name}}TestConfig(t, string(contents))
+	_, err = New{{.Struct}}()
+	if err != nil && strings.HasPrefix(err.Error(), "invalid configuration") {
+		t.Skipf("{{.File}} is waiting for values: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("New{{.Struct}}() failed to load {{.File}}: %v", err)
+	}
+}
+{{- if .Invalid}}
+
+func TestNew{{.Struct}}Validation(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{{- range .Invalid}}
+		{name: {{printf "%q" .Name}}, contents: {{.Contents}}},
+		{{- end}}
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			write{{$.Name}}TestConfig(t, test.contents)
+			_, err := New{{$.Struct}}()
+			if err == nil {
+				t.Errorf("New{{$.Struct}}() accepted a config breaking the rule")
+			}
+		})
+	}
+}
+{{- end}}
+`))
+
+// testProperty is a property of the category the generated tests set
+type testProperty struct {
+	path     []string
+	field    string
+	typeName string
+	kind     valueKind
+	// element is the kind of the items of slices and of the values of maps
+	element valueKind
+	value   any
+	rules   map[string]string
+}
+
+// valueKind classifies the types of properties by the values the generator writes for them
+type valueKind int
+
+const (
+	otherValue valueKind = iota
+	stringValue
+	boolValue
+	integerValue
+	floatValue
+	durationValue
+	sliceValue
+	mapValue
+)
+
+// classifyType returns the kind of the type and, for slices and maps with string keys, the kind of their items
+func classifyType(expr ast.Expr) (valueKind, valueKind) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return stringValue, otherValue
+		case "bool":
+			return boolValue, otherValue
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+			return integerValue, otherValue
+		case "float32", "float64":
+			return floatValue, otherValue
+		}
+	case *ast.SelectorExpr:
+		if types.ExprString(t) == "time.Duration" {
+			return durationValue, otherValue
+		}
+	case *ast.ArrayType:
+		if t.Len == nil {
+			element, _ := classifyType(t.Elt)
+			return sliceValue, element
+		}
+	case *ast.MapType:
+		key, _ := classifyType(t.Key)
+		element, _ := classifyType(t.Value)
+		if key != stringValue {
+			element = otherValue
+		}
+		return mapValue, element
+	}
+	return otherValue, otherValue
+}
+
+func (k valueKind) numeric() bool {
+	return k == integerValue || k == floatValue
+}
+
+// literal tells whether the generated tests compare the values of the kind with literals
+func (k valueKind) literal() bool {
+	return k == stringValue || k == boolValue || k.numeric()
+}
+
+// fieldAssertion checks the field after loading, by comparing it with Expected or, without it, by
+// checking it's not zero
+type fieldAssertion struct {
+	Field    string
+	Expected string
+}
+
+type invalidTestCase struct {
+	Name     string
+	Contents string
+}
+
+func categoryTestPath(category string) string {
+	return fmt.Sprintf("%s/%s_test.go", config.ConfigSourceDirectory, category)
+}
+
+// generateCategoryTests writes the tests loading the category from a file setting every property and
+// from files breaking each validation rule
+func generateCategoryTests(category string) error {
+	fileFormat, found := findCategoryFormat(category)
+	if !found {
+		return fmt.Errorf("config file of category %s not found", category)
+	}
+	source, err := parseConfigSource(category)
+	if err != nil {
+		return err
+	}
+	structType := source.categoryStruct(category)
+	if structType == nil {
+		return fmt.Errorf("struct %s not found", categoryStructName(category))
+	}
+	properties := collectTestProperties(structType, nil, nil, fileFormat)
+	valid, err := renderTestConfig(fileFormat, properties)
+	if err != nil {
+		return err
+	}
+	var assertions []fieldAssertion
+	var usesReflect bool
+	for _, property := range properties {
+		assertion := fieldAssertion{Field: property.field}
+		if property.kind.literal() {
+			assertion.Expected = testLiteral(property.value)
+		} else {
+			usesReflect = true
+		}
+		assertions = append(assertions, assertion)
+	}
+	var invalid []invalidTestCase
+	for i, property := range properties {
+		for _, rule := range sortedRules(property.rules) {
+			value, ok := invalidTestValue(property, rule)
+			// a default breaking the rule already leaves no value to break it with
+			if !ok || reflect.DeepEqual(value, property.value) {
+				continue
+			}
+			broken := append([]testProperty{}, properties...)
+			broken[i].value = value
+			contents, err := renderTestConfig(fileFormat, broken)
+			if err != nil {
+				return err
+			}
+			invalid = append(invalid, invalidTestCase{Name: fmt.Sprintf("%s %s", property.field, rule), Contents: contents})
+		}
+	}
+	name := fieldName(category)
+	var code bytes.Buffer
+	err = categoryTestTemplate.Execute(&code, map[string]any{
+		"Name":     name,
+		"Struct":   categoryStructName(category),
+		"Contents": category + "TestConfig",
+		"File":     configFilePath(category, fileFormat),
+		// the tests run in the config package, the file is relative to the project's root
+		"ProjectFile": path.Join(strings.Repeat("../", strings.Count(config.ConfigSourceDirectory, "/")+1), configFilePath(category, fileFormat)),
+		"Valid":       valid,
+		"Assertions":  assertions,
+		"Invalid":     invalid,
+		"Reflect":     usesReflect,
+		"Source":      loadsFromSource(source, category),
+		"Category":    category,
+	})
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	return os.WriteFile(categoryTestPath(category), formattedCode, 0644)
+}
+
+// collectTestProperties returns the properties of the struct, including the ones in sections, with the
+// values the tests set them to
+func collectTestProperties(structType *ast.StructType, keys []string, fields []string, fileFormat configFormat) []testProperty {
+	var properties []testProperty
+	for _, field := range structType.Fields.List {
+		tag := fieldTag(field)
+		for _, name := range field.Names {
+			if !name.IsExported() {
+				continue
+			}
+			path := append(append([]string{}, keys...), propertyKey(name.Name, tag))
+			fieldPath := append(append([]string{}, fields...), name.Name)
+			if nested, ok := field.Type.(*ast.StructType); ok {
+				properties = append(properties, collectTestProperties(nested, path, fieldPath, fileFormat)...)
+				continue
+			}
+			property := testProperty{
+				path:     path,
+				field:    strings.Join(fieldPath, "."),
+				typeName: types.ExprString(field.Type),
+				rules:    validationRules(tag),
+			}
+			property.kind, property.element = classifyType(field.Type)
+			value, ok := validTestValue(property, tag, fileFormat)
+			if !ok {
+				continue
+			}
+			property.value = value
+			properties = append(properties, property)
+		}
+	}
+	return properties
+}
+
+// validTestValue returns the default of the property or, without one, a value which isn't zero and satisfies its rules
+func validTestValue(property testProperty, tag reflect.StructTag, fileFormat configFormat) (any, bool) {
+	if defaultValue, ok := tag.Lookup("default"); ok && defaultValue != "" {
+		if property.kind == stringValue {
+			return defaultValue, true
+		}
+		value, err := propertyOptions{Default: defaultValue}.value(property.typeName)
+		return value, err == nil
+	}
+	options := strings.Fields(property.rules["oneof"])
+	switch property.kind {
+	case stringValue:
+		if len(options) > 0 {
+			return options[0], true
+		}
+		value := "test"
+		if limit, ok := ruleLimit(property.rules, "min"); ok && int(limit) > len(value) {
+			value = strings.Repeat("x", int(limit))
+		}
+		if limit, ok := ruleLimit(property.rules, "max"); ok && int(limit) < len(value) {
+			value = strings.Repeat("x", int(limit))
+		}
+		return value, true
+	case boolValue:
+		return true, true
+	case integerValue, floatValue:
+		if len(options) > 0 {
+			value, err := strconv.ParseFloat(options[0], 64)
+			return numericTestValue(property.kind, value), err == nil
+		}
+		value := 42.0
+		if limit, ok := ruleLimit(property.rules, "min"); ok && value < limit {
+			value = limit
+		}
+		if limit, ok := ruleLimit(property.rules, "max"); ok && value > limit {
+			value = limit
+		}
+		return numericTestValue(property.kind, value), true
+	case durationValue:
+		return "1m30s", true
+	}
+	// dotenv files hold nothing but plain values
+	if fileFormat.Name() == "dotenv" {
+		return nil, false
+	}
+	item, ok := map[valueKind]any{stringValue: "test", integerValue: 42}[property.element]
+	if !ok {
+		return nil, false
+	}
+	switch property.kind {
+	case sliceValue:
+		return []any{item}, true
+	case mapValue:
+		return map[string]any{"key": item}, true
+	}
+	return nil, false
+}
+
+// invalidTestValue returns a value of the property breaking the rule
+func invalidTestValue(property testProperty, rule string) (any, bool) {
+	limit, hasLimit := ruleLimit(property.rules, rule)
+	switch rule {
+	case "required":
+		switch property.kind {
+		case stringValue:
+			return "", true
+		case boolValue:
+			return false, true
+		case integerValue, floatValue:
+			return 0, true
+		case durationValue:
+			return "0s", true
+		case sliceValue:
+			return []any{}, true
+		case mapValue:
+			return map[string]any{}, true
+		}
+	case "min", "max":
+		if !hasLimit {
+			return nil, false
+		}
+		size := limit + 1
+		if rule == "min" {
+			size = limit - 1
+		}
+		switch {
+		case property.kind.numeric():
+			return numericTestValue(property.kind, size), true
+		case property.kind == stringValue && size >= 0:
+			return strings.Repeat("x", int(size)), true
+		case property.kind == sliceValue && size >= 0:
+			// the items repeat the first one of the valid value, a slice without any can't be filled
+			valid, _ := property.value.([]any)
+			if size > 0 && len(valid) == 0 {
+				return nil, false
+			}
+			items := make([]any, int(size))
+			for i := range items {
+				items[i] = valid[0]
+			}
+			return items, true
+		}
+	case "oneof":
+		options := strings.Fields(property.rules["oneof"])
+		if property.kind == stringValue {
+			return "not-" + strings.Join(options, "-"), true
+		}
+		if property.kind.numeric() {
+			highest := 0.0
+			for _, option := range options {
+				value, err := strconv.ParseFloat(option, 64)
+				if err != nil {
+					return nil, false
+				}
+				if value > highest {
+					highest = value
+				}
+			}
+			return numericTestValue(property.kind, highest+1), true
+		}
+	}
+	return nil, false
+}
+
+// numericTestValue keeps integer fields from getting fractional values in the files
+func numericTestValue(kind valueKind, value float64) any {
+	if kind == integerValue {
+		return int64(value)
+	}
+	return value
+}
+
+func ruleLimit(rules map[string]string, rule string) (float64, bool) {
+	argument, ok := rules[rule]
+	if !ok {
+		return 0, false
+	}
+	limit, err := strconv.ParseFloat(argument, 64)
+	return limit, err == nil
+}
+
+func sortedRules(rules map[string]string) []string {
+	var names []string
+	for _, name := range []string{"required", "min", "max", "oneof"} {
+		if _, ok := rules[name]; ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// renderTestConfig returns the Go literal of a config file setting the properties
+func renderTestConfig(fileFormat configFormat, properties []testProperty) (string, error) {
+	file, err := os.CreateTemp("", "config-*."+fileFormat.Extension())
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(fileFormat.InitialContents())
+	if err != nil {
+		file.Close()
+		return "", err
+	}
+	err = file.Close()
+	if err != nil {
+		return "", err
+	}
+	for _, property := range properties {
+		err = fileFormat.SetProperty(file.Name(), property.path, property.value)
+		if err != nil {
+			return "", err
+		}
+	}
+	contents, err := os.ReadFile(file.Name())
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(string(contents), "`") {
+		return strconv.Quote(string(contents)), nil
+	}
+	return "`" + string(contents) + "`", nil
+}
+
+func testLiteral(value any) string {
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
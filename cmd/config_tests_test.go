@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// TestEmptyCategoryTestsVet generates a project with a category without properties and vets the tests
+// generated for it, which load the category without asserting anything
+func TestEmptyCategoryTestsVet(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates a project and runs go vet on it")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(workingDirectory)
+	})
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec := &projectSpec{Name: "empty"}
+	err = spec.complete()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = generateProject(spec, initOptions{})
+	if err != nil {
+		t.Fatalf("generateProject() failed: %v", err)
+	}
+	err = createCategoryWithOptions("empty", categoryOptions{})
+	if err != nil {
+		t.Fatalf("createCategoryWithOptions() failed: %v", err)
+	}
+	t.Setenv("GOFLAGS", "-mod=mod")
+	err = runGo("mod", "download")
+	if err != nil {
+		t.Skipf("the modules of the generated project can't be downloaded: %v", err)
+	}
+	err = runGo("vet", "./...")
+	if err != nil {
+		t.Errorf("go vet failed on the generated project: %v", err)
+	}
+}
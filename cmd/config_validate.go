@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"template/config"
+)
+
+const validationSourceName = "validate.go"
+
+// validationSourceContents is the helper enforcing the validate tags written by config add
+const validationSourceContents = `package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// validate checks the rules in the validate tags of the config struct. Supported rules are
+// required, min, max and oneof.
+func validate(config any) error {
+	var problems []string
+	validateStruct(reflect.Indirect(reflect.ValueOf(config)), "", &problems)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func validateStruct(value reflect.Value, prefix string, problems *[]string) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		fieldValue := value.Field(i)
+		name := prefix + field.Name
+		if fieldValue.Kind() == reflect.Struct {
+			validateStruct(fieldValue, name+".", problems)
+		}
+		for _, rule := range strings.Split(field.Tag.Get("validate"), ",") {
+			ruleName, argument, _ := strings.Cut(strings.TrimSpace(rule), "=")
+			if problem := checkRule(fieldValue, ruleName, argument); problem != "" {
+				*problems = append(*problems, name+" "+problem)
+			}
+		}
+	}
+}
+
+func checkRule(value reflect.Value, rule string, argument string) string {
+	switch rule {
+	case "required":
+		if value.IsZero() {
+			return "is required"
+		}
+	case "min", "max":
+		limit, err := strconv.ParseFloat(argument, 64)
+		if err != nil {
+			return fmt.Sprintf("has invalid %s rule %q", rule, argument)
+		}
+		size, ok := measure(value)
+		if ok && rule == "min" && size < limit {
+			return fmt.Sprintf("must be at least %s", argument)
+		}
+		if ok && rule == "max" && size > limit {
+			return fmt.Sprintf("must be at most %s", argument)
+		}
+	case "oneof":
+		actual := fmt.Sprint(value.Interface())
+		for _, option := range strings.Fields(argument) {
+			if option == actual {
+				return ""
+			}
+		}
+		return fmt.Sprintf("must be one of %s", argument)
+	}
+	return ""
+}
+
+// measure returns what min and max rules compare: the value of numbers and the length of strings and collections
+func measure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return float64(value.Len()), true
+	}
+	return 0, false
+}
+`
+
+// ensureValidationSource writes the validation helper into the config package unless it's there already
+func ensureValidationSource() error {
+	path := fmt.Sprintf("%s/%s", config.ConfigSourceDirectory, validationSourceName)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return os.WriteFile(path, []byte(validationSourceContents), 0644)
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"template/config"
+	"text/template"
+)
+
+var loaderTemplate = template.Must(template.New("loader").Parse(`package config
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// {{.Loader}} holds the current {{.Struct}} and reloads it when {{.File}} changes.
+// A changed file failing to load or validate is rejected and the last valid config stays in use.
+type {{.Loader}} struct {
+	viper          *viper.Viper
+	current        atomic.Pointer[{{.Struct}}]
+	mutex          sync.Mutex
+	callbacks      []func(old, new *{{.Struct}})
+	errorCallbacks []func(err error)
+}
+
+// New{{.Loader}} loads and validates {{.File}}. Call Watch to start reloading it on changes. The reloaded
+// configs are read like New{{.Struct}} reads them, with the overrides of the environment and the flags.
+func New{{.Loader}}() (*{{.Loader}}, error) {
+	v, err := {{.Viper}}()
+	if err != nil {
+		return nil, err
+	}
+	loader := &{{.Loader}}{viper: v}
+	config, err := loader.load()
+	if err != nil {
+		return nil, err
+	}
+	loader.current.Store(config)
+	return loader, nil
+}
+
+// Config returns the current configuration. It's shared between callers and must not be modified.
+func (l *{{.Loader}}) Config() *{{.Struct}} {
+	return l.current.Load()
+}
+
+// OnChange registers a callback called with the previous and the new configuration after every accepted reload
+func (l *{{.Loader}}) OnChange(callback func(old, new *{{.Struct}})) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.callbacks = append(l.callbacks, callback)
+}
+
+// OnError registers a callback called with the reason a reload was rejected
+func (l *{{.Loader}}) OnError(callback func(err error)) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.errorCallbacks = append(l.errorCallbacks, callback)
+}
+
+// Watch starts watching the config file for changes
+func (l *{{.Loader}}) Watch() {
+	l.viper.OnConfigChange(func(fsnotify.Event) {
+		l.reload()
+	})
+	l.viper.WatchConfig()
+}
+
+func (l *{{.Loader}}) load() (*{{.Struct}}, error) {
+	err := l.viper.ReadInConfig()
+	if err != nil {
+		return nil, err
+	}
+	config := &{{.Struct}}{}
+	err = l.viper.Unmarshal(config)
+	if err != nil {
+		return nil, err
+	}
+	err = validate(config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (l *{{.Loader}}) reload() {
+	config, err := l.load()
+	l.mutex.Lock()
+	callbacks := append([]func(old, new *{{.Struct}}){}, l.callbacks...)
+	errorCallbacks := append([]func(err error){}, l.errorCallbacks...)
+	l.mutex.Unlock()
+	if err != nil {
+		for _, callback := range errorCallbacks {
+			callback(err)
+		}
+		return
+	}
+	old := l.current.Swap(config)
+	for _, callback := range callbacks {
+		callback(old, config)
+	}
+}
+`))
+
+func categoryLoaderName(category string) string {
+	return fieldName(category) + "Loader"
+}
+
+func categoryLoaderPath(category string) string {
+	return fmt.Sprintf("%s/%s_loader.go", config.ConfigSourceDirectory, category)
+}
+
+// createCategoryLoader generates the loader keeping the category's config up to date with its file
+func createCategoryLoader(category string, fileFormat configFormat) error {
+	var code bytes.Buffer
+	err := loaderTemplate.Execute(&code, map[string]string{
+		"Loader": categoryLoaderName(category),
+		"Struct": categoryStructName(category),
+		"File":   configFilePath(category, fileFormat),
+		"Viper":  categoryViperName(category),
+	})
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	return os.WriteFile(categoryLoaderPath(category), formattedCode, 0644)
+}
@@ -0,0 +1,120 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"template/config"
+
+	"golang.org/x/mod/modfile"
+)
+
+const goModFileName = "go.mod"
+
+// moduleVersions pins the version of every module the generated code may import. Generators require
+// them in go.mod directly instead of resolving versions with go get, so a project can be generated
+// offline and built against a pre-populated module cache or a vendor directory.
+var moduleVersions = map[string]string{
+	"github.com/caarlos0/env/v11":            "v11.4.1",
+	"github.com/gin-gonic/gin":               "v1.12.0",
+	"github.com/go-sql-driver/mysql":         "v1.10.1",
+	"github.com/knadh/koanf/parsers/dotenv":  "v1.1.1",
+	"github.com/knadh/koanf/parsers/json":    "v1.0.1",
+	"github.com/knadh/koanf/parsers/toml/v2": "v2.1.0",
+	"github.com/knadh/koanf/parsers/yaml":    "v1.1.1",
+	"github.com/knadh/koanf/providers/file":  "v1.2.1",
+	"github.com/knadh/koanf/v2":              "v2.3.7",
+	"github.com/labstack/echo/v4":            "v4.16.0",
+	"github.com/lib/pq":                      "v1.12.3",
+	"github.com/robfig/cron/v3":              "v3.0.1",
+	"github.com/spf13/pflag":                 "v1.0.10",
+	"github.com/spf13/viper":                 "v1.21.0",
+	"google.golang.org/grpc":                 "v1.84.0",
+	"google.golang.org/protobuf":             "v1.36.11",
+	"gopkg.in/yaml.v3":                       "v3.0.1",
+	"modernc.org/sqlite":                     "v1.59.0",
+}
+
+// writeGoMod writes the go.mod of a new project, without requirements
+func writeGoMod(module string) error {
+	file := &modfile.File{}
+	err := file.AddModuleStmt(module)
+	if err != nil {
+		return err
+	}
+	err = file.AddGoStmt(config.GoVersion)
+	if err != nil {
+		return err
+	}
+	contents, err := file.Format()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(goModFileName, contents, 0644)
+}
+
+func readGoMod() (*modfile.File, error) {
+	contents, err := os.ReadFile(goModFileName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.mod, is this the project's directory? %w", err)
+	}
+	return modfile.Parse(goModFileName, contents, nil)
+}
+
+// requireModules adds the pinned versions of the modules to the project's go.mod. Modules the project
+// requires already keep their version.
+func requireModules(modules ...string) error {
+	file, err := readGoMod()
+	if err != nil {
+		return err
+	}
+	required := map[string]bool{}
+	for _, requirement := range file.Require {
+		required[requirement.Mod.Path] = true
+	}
+	changed := false
+	for _, module := range modules {
+		if required[module] {
+			continue
+		}
+		version, ok := moduleVersions[module]
+		if !ok {
+			return fmt.Errorf("no pinned version of module %s", module)
+		}
+		err = file.AddRequire(module, version)
+		if err != nil {
+			return err
+		}
+		required[module] = true
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	file.SortBlocks()
+	file.Cleanup()
+	contents, err := file.Format()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(goModFileName, contents, 0644)
+}
+
+// runGo runs the go command, returning what it printed to stderr when it fails
+func runGo(args ...string) error {
+	return runCommand("go", args...)
+}
+
+// runCommand runs the program, returning what it printed to stderr when it fails
+func runCommand(name string, args ...string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("%s %s: %w\n%s", name, strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// generateCmd groups the commands regenerating code from the project's sources
+var generateCmd = &cobra.Command{
+	Use:               "generate",
+	Short:             "Regenerate code from the project's sources",
+	Long:              `Regenerate code from the project's sources, like the Go stubs of its proto files.`,
+	PersistentPreRunE: enterProjectRoot,
+}
+
+func init() {
+	rootCmd.AddCommand(generateCmd)
+}
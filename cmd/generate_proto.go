@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+var protoPlugins = []string{"protoc-gen-go", "protoc-gen-go-grpc"}
+
+// generateProtoCmd represents the generate proto command
+var generateProtoCmd = &cobra.Command{
+	Use:   "proto",
+	Short: "Regenerate the Go stubs of the proto files",
+	Long: `Regenerate the Go stubs of the proto files under proto/ into pkg/proto, with buf when it's installed
+and protoc otherwise. Both need the protoc-gen-go and protoc-gen-go-grpc plugins. The proto files don't
+declare a go_package, their Go import path is the module path followed by pkg/proto and their directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateProtoStubs()
+	},
+}
+
+func generateProtoStubs() error {
+	module, err := projectModulePath()
+	if err != nil {
+		return err
+	}
+	files, err := protoFiles()
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no proto files in %s", protoDirectory)
+	}
+	for _, plugin := range protoPlugins {
+		if _, err := exec.LookPath(plugin); err != nil {
+			return fmt.Errorf("%s isn't installed, install it with go install, see https://grpc.io/docs/languages/go/quickstart", plugin)
+		}
+	}
+	err = os.MkdirAll(protoStubsDirectory, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	options := protoPluginOptions(module, files)
+	if _, err := exec.LookPath("buf"); err == nil {
+		return generateWithBuf(options)
+	}
+	if _, err := exec.LookPath("protoc"); err == nil {
+		return generateWithProtoc(options, files)
+	}
+	return fmt.Errorf("neither buf nor protoc is installed, install one of them to generate the stubs, see https://buf.build/docs/installation")
+}
+
+// protoFiles returns the proto files, relative to the proto directory the way they import each other
+func protoFiles() ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(protoDirectory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || filepath.Ext(path) != ".proto" {
+			return nil
+		}
+		relative, err := filepath.Rel(protoDirectory, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(relative))
+		return nil
+	})
+	return files, err
+}
+
+// protoPluginOptions maps every proto file to its Go package with the plugins' M option, instead of a
+// go_package in the proto file
+func protoPluginOptions(module string, files []string) []string {
+	options := []string{"paths=source_relative"}
+	for _, file := range files {
+		directory := path.Dir(file)
+		importPath := path.Join(module, protoStubsDirectory, directory)
+		options = append(options, fmt.Sprintf("M%s=%s;%s", file, importPath, protoGoPackageName(directory)))
+	}
+	return options
+}
+
+// protoGoPackageName names the Go package of the directory the way buf does: greeter/v1 is greeterv1
+func protoGoPackageName(directory string) string {
+	elements := strings.Split(directory, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && len(name) > 1 && name[0] == 'v' && strings.Trim(name[1:], "0123456789") == "" {
+		name = elements[len(elements)-2] + name
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, name)
+}
+
+// generateWithBuf runs buf with a template built from the options, the project's buf.yaml declaring
+// the proto directory as the module
+func generateWithBuf(options []string) error {
+	type plugin struct {
+		Local string   `json:"local"`
+		Out   string   `json:"out"`
+		Opt   []string `json:"opt"`
+	}
+	generation := struct {
+		Version string   `json:"version"`
+		Plugins []plugin `json:"plugins"`
+	}{Version: "v2"}
+	for _, name := range protoPlugins {
+		generation.Plugins = append(generation.Plugins, plugin{Local: name, Out: protoStubsDirectory, Opt: options})
+	}
+	template, err := json.Marshal(generation)
+	if err != nil {
+		return err
+	}
+	return runCommand("buf", "generate", "--template", string(template))
+}
+
+func generateWithProtoc(options []string, files []string) error {
+	args := []string{"--proto_path=" + protoDirectory}
+	for _, language := range []string{"go", "go-grpc"} {
+		args = append(args, fmt.Sprintf("--%s_out=%s", language, protoStubsDirectory))
+		for _, option := range options {
+			args = append(args, fmt.Sprintf("--%s_opt=%s", language, option))
+		}
+	}
+	return runCommand("protoc", append(args, files...)...)
+}
+
+func init() {
+	generateCmd.AddCommand(generateProtoCmd)
+}
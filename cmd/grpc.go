@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"embed"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+const (
+	grpcModule     = "google.golang.org/grpc"
+	protobufModule = "google.golang.org/protobuf"
+	// protoDirectory holds the project's proto files, the import root of protoc and buf
+	protoDirectory = "proto"
+	// protoStubsDirectory holds the Go stubs generated from the proto files, in the same tree
+	protoStubsDirectory = "pkg/proto"
+	grpcServerPath      = "pkg/infra/grpcserver/server.go"
+	grpcAddressProperty = "grpcAddress"
+)
+
+// grpcTemplates holds the starter service of grpc entrypoints: its proto file, buf's module config and the
+// stubs generated from it by template generate proto. The stubs don't depend on the module path, which
+// generate proto passes to the plugins instead of the proto files declaring a go_package.
+//
+//go:embed templates/grpc
+var grpcTemplates embed.FS
+
+// grpcStarterFiles maps the starter service's files in the project to their template
+var grpcStarterFiles = map[string]string{
+	protoDirectory + "/greeter/v1/greeter.proto":           "templates/grpc/greeter.proto",
+	protoStubsDirectory + "/greeter/v1/greeter.pb.go":      "templates/grpc/greeter.pb.go.tmpl",
+	protoStubsDirectory + "/greeter/v1/greeter_grpc.pb.go": "templates/grpc/greeter_grpc.pb.go.tmpl",
+	"buf.yaml": "templates/grpc/buf.yaml",
+}
+
+// grpcServerContents builds the servers of the grpc entrypoints
+const grpcServerContents = `package grpcserver
+
+import (
+	"context"
+	"log"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// New returns a server logging every call, turning panics into Internal errors and serving the health
+// and reflection services. The health server reports every service as serving.
+func New(options ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+	options = append(options,
+		grpc.ChainUnaryInterceptor(recoverUnary, logUnary),
+		grpc.ChainStreamInterceptor(recoverStream, logStream),
+	)
+	server := grpc.NewServer(options...)
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+	return server, healthServer
+}
+
+func logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	log.Printf("%s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+	return resp, err
+}
+
+func logStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, stream)
+	log.Printf("%s %s %s", info.FullMethod, status.Code(err), time.Since(start))
+	return err
+}
+
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, stream)
+}
+
+func recovered(method string, r any) error {
+	log.Printf("%s panicked: %v\n%s", method, r, debug.Stack())
+	return status.Error(codes.Internal, "internal error")
+}
+`
+
+// grpcEntrypoint serves the starter service at the address of the application category
+var grpcEntrypoint = template.Must(template.New("grpc").Parse(`package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"{{.Module}}/pkg/infra/config"
+	"{{.Module}}/pkg/infra/grpcserver"
+	greeterv1 "{{.Module}}/pkg/proto/greeter/v1"
+)
+
+func main() {
+	configuration, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to read configuration file: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	listener, err := net.Listen("tcp", configuration.Application.GrpcAddress)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+	server, healthServer := grpcserver.New()
+	greeterv1.RegisterGreeterServiceServer(server, &greeter{name: configuration.Application.ApplicationName})
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	go func() {
+		<-ctx.Done()
+		healthServer.Shutdown()
+		server.GracefulStop()
+	}()
+	log.Printf("%s serves gRPC at %s", configuration.Application.ApplicationName, listener.Addr())
+	err = server.Serve(listener)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`))
+
+// grpcService implements the starter service next to the entrypoint
+var grpcService = template.Must(template.New("greeter").Parse(`package main
+
+import (
+	"context"
+	"fmt"
+
+	greeterv1 "{{.Module}}/pkg/proto/greeter/v1"
+)
+
+// greeter implements the GreeterService of proto/greeter/v1/greeter.proto
+type greeter struct {
+	greeterv1.UnimplementedGreeterServiceServer
+	name string
+}
+
+func (g *greeter) SayHello(ctx context.Context, request *greeterv1.SayHelloRequest) (*greeterv1.SayHelloResponse, error) {
+	return &greeterv1.SayHelloResponse{Message: fmt.Sprintf("Hello %s! Welcome to %s!", request.GetName(), g.name)}, nil
+}
+`))
+
+// grpcApplication serves gRPC with the starter service, health checks and reflection
+type grpcApplication struct{}
+
+func (grpcApplication) Name() string { return applicationTypeGrpc }
+func (grpcApplication) Description() string {
+	return "gRPC server with health checks, reflection and a starter service"
+}
+
+func (grpcApplication) Files(entrypoint entrypointContext) (map[string]string, error) {
+	files := map[string]string{}
+	for path, source := range map[string]*template.Template{
+		entrypoint.mainPath(): grpcEntrypoint,
+		filepath.Join(filepath.Dir(entrypoint.mainPath()), "greeter.go"): grpcService,
+	} {
+		var code bytes.Buffer
+		err := source.Execute(&code, map[string]string{"Module": entrypoint.Module})
+		if err != nil {
+			return nil, err
+		}
+		files[path] = code.String()
+	}
+	return files, nil
+}
+
+func (grpcApplication) Dependencies(entrypointContext) []string {
+	return []string{grpcModule, protobufModule}
+}
+
+// PostSteps adds the address the entrypoints listen at to the application category, then the starter
+// service and the server package unless an earlier grpc entrypoint added them
+func (grpcApplication) PostSteps(entrypoint entrypointContext) error {
+	exists, err := propertyExistsInCategory(initialCategory, "", grpcAddressProperty)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		property := propertySpec{Name: grpcAddressProperty, Default: ":50051", Description: "Address the gRPC server listens on"}
+		err = property.add(initialCategory)
+		if err != nil {
+			return err
+		}
+	}
+	for path, name := range grpcStarterFiles {
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		contents, err := grpcTemplates.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		err = writeProjectFile(path, contents)
+		if err != nil {
+			return err
+		}
+	}
+	if _, err := os.Stat(grpcServerPath); err == nil {
+		return nil
+	}
+	return writeProjectFile(grpcServerPath, []byte(grpcServerContents))
+}
+
+func writeProjectFile(path string, contents []byte) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, contents, 0644)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"github.com/spf13/cobra"
 	"go/ast"
@@ -9,225 +10,487 @@ import (
 	"go/token"
 	"golang.org/x/tools/go/ast/astutil"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"template/config"
 )
 
 const (
-	applicationTypeApi = "api"
-	applicationTypeCli = "cli"
+	applicationTypeApi    = "api"
+	applicationTypeCli    = "cli"
+	applicationTypeWorker = "worker"
+	applicationTypeCron   = "cron"
+	applicationTypeGrpc   = "grpc"
 )
 
+// initialCategory is the category every project starts with
+const initialCategory = "application"
+
 var forceCreate bool
-var applicationType string
+var initApplicationType string
+var projectFormat string
+var configLibrary string
+var specPath string
+var modulePath string
+var tidyModule bool
+var useWorkspace bool
+var interactiveInit bool
+var inPlace bool
+
+// initOptions are the choices of init about the directory the project is generated in
+type initOptions struct {
+	// Force overwrites an existing project directory, or the generated files of a project generated in place
+	Force bool
+	// InPlace generates the project in the current directory, adopting its go.mod
+	InPlace bool
+}
 
 // initCmd represents the init command
 var initCmd = &cobra.Command{
-	Use:   "init",
+	Use:   "init [project_name]",
 	Short: "A command to initialize new project",
-	Long:  `This command allows you to create new project, given the project's name`,
-	Args:  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	Long: `This command allows you to create new project, given the project's name. With --spec the whole
+project is declared in a yaml file: module path, application types, HTTP framework, config
+categories with their properties, components (database, logging, metrics) and template pack.
+With --module the project's Go module path differs from its name, init --module github.com/org/app
+generates the module github.com/org/app in the directory app. A name given next to --spec or --module
+overrides the spec's or the module's name. init . or init --in-place generate the project in the
+current directory, adopting its go.mod and keeping its entrypoints, and refuse to overwrite the
+config, its package or the manifest unless --forceCreate is given. Run without arguments in a
+terminal, or with --interactive, init asks for the choices and saves them as the project's spec file, project.yaml.
+-t library generates a library instead of applications: a root package with its doc and example test,
+an internal package and no entrypoints. config create then adds the options structs of the library.
+With --workspace the project is added to the nearest go.work above the current directory.`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		applicationName := args[0]
-		err := createDirectory(applicationName, forceCreate) // todo delegate project structure creation to separate unit
-		if err != nil {
-			return err
+		if interactiveInit || (len(args) == 0 && specPath == "" && modulePath == "" && !inPlace && isTerminal(os.Stdin)) {
+			return initInteractively(cmd)
 		}
-		defer func() {
-			if err != nil {
-				removalErr := os.RemoveAll(applicationName)
-				if removalErr != nil {
-					err = fmt.Errorf("%w\nfailed to remove created directory: %s", err, removalErr)
-				}
-			}
-		}()
-		err = os.Chdir(applicationName)
-		if err != nil {
-			return err
-		}
-		err = initializeProject(applicationName)
+		spec, err := initSpec(cmd, args)
 		if err != nil {
 			return err
 		}
-		err = initializeEntrypoint(applicationType)
+		workspace, err := initWorkspace()
 		if err != nil {
 			return err
 		}
-		err = initializeConfig(applicationName, applicationType, config.ConfigLibraryName)
+		err = generateProject(spec, initOptions{Force: forceCreate, InPlace: inPlace})
 		if err != nil {
 			return err
 		}
-		err = initializeGeneratorData()
+		err = joinWorkspace(workspace)
 		if err != nil {
 			return err
 		}
-		return nil
+		return tidyProject()
 	},
 }
 
-func createDirectory(applicationName string, force bool) error {
-	if force {
-		err := os.RemoveAll(applicationName)
+// tidyProject runs go mod tidy in the generated project when asked with --tidy. Otherwise go.mod lists
+// only the pinned direct requirements, completed by the first build with GOFLAGS=-mod=mod.
+func tidyProject() error {
+	if !tidyModule {
+		return nil
+	}
+	return runGo("mod", "tidy")
+}
+
+// initWorkspace returns the go.work the generated project joins with --workspace, the nearest one above the
+// current directory. It's looked for before anything is generated.
+func initWorkspace() (string, error) {
+	if !useWorkspace {
+		return "", nil
+	}
+	workspace, err := findWorkspace(".")
+	if err != nil {
+		return "", err
+	}
+	if workspace == "" {
+		return "", fmt.Errorf("no %s found in the current directory or above it, create one with %s workspace init", goWorkFileName, config.AppName)
+	}
+	return workspace, nil
+}
+
+// joinWorkspace adds the project generated in the current directory to the workspace, if any
+func joinWorkspace(workspace string) error {
+	if workspace == "" {
+		return nil
+	}
+	return addToWorkspace(workspace, ".")
+}
+
+// initInteractively generates the project the user declares in the wizard and saves its spec in the project
+func initInteractively(cmd *cobra.Command) error {
+	w := &wizard{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+	spec, err := w.runWizard()
+	if err != nil || spec == nil {
+		return err
+	}
+	err = spec.complete()
+	if err != nil {
+		return err
+	}
+	err = spec.validate()
+	if err != nil {
+		return err
+	}
+	workspace, err := initWorkspace()
+	if err != nil {
+		return err
+	}
+	err = generateProject(spec, initOptions{Force: forceCreate, InPlace: inPlace})
+	if err != nil {
+		return err
+	}
+	err = joinWorkspace(workspace)
+	if err != nil {
+		return err
+	}
+	err = saveSpec(spec)
+	if err != nil {
+		return err
+	}
+	return tidyProject()
+}
+
+// initSpec returns the spec read from --spec or, without it, the spec made of the flags
+func initSpec(cmd *cobra.Command, args []string) (*projectSpec, error) {
+	spec := &projectSpec{}
+	if specPath != "" {
+		var err error
+		spec, err = readProjectSpec(specPath)
 		if err != nil {
-			return fmt.Errorf("failed to remove existing directory")
+			return nil, err
+		}
+	} else {
+		if len(args) == 0 && modulePath == "" && !inPlace {
+			return nil, fmt.Errorf("the project's name is missing")
+		}
+		spec.Applications = []string{initApplicationType}
+		spec.Config.Library = configLibrary
+		if cmd.Flags().Changed("format") {
+			spec.Config.Format = projectFormat
 		}
 	}
-	err := os.Mkdir(applicationName, os.ModePerm)
+	if len(args) == 1 && args[0] == "." {
+		inPlace = true
+	} else if len(args) == 1 {
+		spec.Name = args[0]
+	}
+	if modulePath != "" {
+		spec.Module = modulePath
+	}
+	if inPlace {
+		err := adoptCurrentDirectory(spec)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := spec.complete()
 	if err != nil {
-		return fmt.Errorf("failed to create dir for project: %w", err)
+		return nil, err
 	}
-	return nil
+	return spec, spec.validate()
 }
 
-func initializeProject(projectName string) error {
-	cmd := exec.Command("go", "mod", "init", projectName)
-	err := cmd.Run()
+// adoptCurrentDirectory names the project after the current directory and takes the module path of its
+// go.mod, when it has one
+func adoptCurrentDirectory(spec *projectSpec) error {
+	directory, err := os.Getwd()
 	if err != nil {
 		return err
 	}
-	cmd = exec.Command("go", "mod", "tidy")
-	err = cmd.Run()
+	spec.Name = filepath.Base(directory)
+	if _, err := os.Stat(goModFileName); os.IsNotExist(err) {
+		return nil
+	}
+	existing, err := projectModulePath()
 	if err != nil {
 		return err
 	}
+	if spec.Module != "" && spec.Module != existing {
+		return fmt.Errorf("the directory is the module %s already, not %s", existing, spec.Module)
+	}
+	spec.Module = existing
 	return nil
 }
 
-// todo implement api and use gin/echo for it. Create dir for middleware
-func initializeEntrypoint(appType string) error {
-	firstEntrypointDirector := fmt.Sprintf("cmd/%s", appType)
-	var contents string
-	if appType == "api" {
-		cmd := exec.Command("go", "get", "-u", "github.com/gin-gonic/gin")
-		err := cmd.Run()
+// generateProject generates the project in a new directory named after it. The directory is removed when
+// anything fails. In place, the project is generated in the current directory, which is never removed.
+func generateProject(spec *projectSpec, options initOptions) (err error) {
+	backend, err := resolveConfigBackend(spec.Config.Library)
+	if err != nil {
+		return err
+	}
+	fileFormat, err := resolveConfigFormat(spec.Config.Format)
+	if err != nil {
+		return err
+	}
+	if options.InPlace {
+		return generateProjectFiles(spec, options, fileFormat, backend)
+	}
+	err = createDirectory(spec.Name, options.Force) // todo delegate project structure creation to separate unit
+	if err != nil {
+		return err
+	}
+	projectDirectory, err := filepath.Abs(spec.Name)
+	if err != nil {
+		return err
+	}
+	defer func() {
 		if err != nil {
-			return err
+			removalErr := os.RemoveAll(projectDirectory)
+			if removalErr != nil {
+				err = fmt.Errorf("%w\nfailed to remove created directory: %s", err, removalErr)
+			}
 		}
-		contents = `package main
-
-import (
-	"fmt"
-  "net/http"
-
-  "github.com/gin-gonic/gin"
-)
-
-func main() {
-	message := fmt.Sprintf("Hello world!")
-  r := gin.Default()
-  r.GET("/", func(c *gin.Context) {
-    c.JSON(http.StatusOK, gin.H{
-      "hello": message,
-    })
-  })
-  r.Run() // listen and serve on 0.0.0.0:8080 (for windows "localhost:8080")
-}`
-	} else {
-		contents = `package main
-
-import (
-	"fmt"
-)
+	}()
+	err = os.Chdir(projectDirectory)
+	if err != nil {
+		return err
+	}
+	return generateProjectFiles(spec, options, fileFormat, backend)
+}
 
-func main() {
-	message := fmt.Sprintf("Hello world!")
-	fmt.Printf(message)
-}`
+// generateProjectFiles generates the project in the current directory. An existing go.mod and existing
+// entrypoints are kept, the files the generator owns are only overwritten with --forceCreate.
+func generateProjectFiles(spec *projectSpec, options initOptions, fileFormat configFormat, backend configBackend) error {
+	if !options.Force {
+		err := checkProjectConflicts(spec)
+		if err != nil {
+			return err
+		}
+	}
+	_, err := os.Stat(goModFileName)
+	if os.IsNotExist(err) {
+		err = initializeProject(spec.Module)
 	}
-	err := os.MkdirAll(firstEntrypointDirector, os.ModePerm)
 	if err != nil {
 		return err
 	}
-	main, err := os.Create(fmt.Sprintf("%s/%s", firstEntrypointDirector, "main.go")) // todo delegate creating entrypoint to separate module, seeded with init data
+	if spec.isLibrary() {
+		return generateLibraryFiles(spec, fileFormat, backend)
+	}
+	var generatedApplications []string
+	var entrypoints []manifestEntrypoint
+	for _, appType := range spec.Applications {
+		entrypoints = append(entrypoints, newManifestEntrypoint(appType, appType, spec.HTTP))
+		if !options.Force && entrypointExists(appType) {
+			fmt.Printf("kept the existing entrypoint of %s, the config isn't loaded in it\n", appType)
+			continue
+		}
+		err = initializeEntrypoint(appType, appType, spec.HTTP, spec.Module)
+		if err != nil {
+			return err
+		}
+		generatedApplications = append(generatedApplications, appType)
+	}
+	err = initializeGeneratorData(&projectManifest{Entrypoints: entrypoints}, fileFormat, backend)
+	if err != nil {
+		return err
+	}
+	err = initializeConfig(spec.Name, spec.Module, generatedApplications, fileFormat, backend)
 	if err != nil {
 		return err
 	}
+	for _, appType := range generatedApplications {
+		err = finishEntrypoint(appType, appType, spec.HTTP, spec.Module)
+		if err != nil {
+			return err
+		}
+	}
+	return generateComponentsAndCategories(spec)
+}
 
-	_, err = fmt.Fprintln(main, contents)
+// generateLibraryFiles generates a library in the current directory: its packages and the config package
+// its options structs are created in, without the application category
+func generateLibraryFiles(spec *projectSpec, fileFormat configFormat, backend configBackend) error {
+	err := initializeGeneratorData(&projectManifest{ProjectType: projectTypeLibrary}, fileFormat, backend)
+	if err != nil {
+		return err
+	}
+	err = initializeConfigDirectories()
+	if err != nil {
+		return err
+	}
+	err = initializeLibrary(spec.Module)
 	if err != nil {
 		return err
 	}
+	return generateComponentsAndCategories(spec)
+}
+
+func generateComponentsAndCategories(spec *projectSpec) error {
+	for _, component := range spec.Components {
+		err := initializeComponent(component, spec.Module)
+		if err != nil {
+			return fmt.Errorf("failed to add component %s: %w", component, err)
+		}
+	}
+	return spec.generateCategories()
+}
+
+// checkProjectConflicts refuses to generate the project over the files the generator owns
+func checkProjectConflicts(spec *projectSpec) error {
+	owned := []string{
+		config.ConfigFileDirectory,
+		config.ConfigSourceDirectory,
+		filepath.Join(config.GeneratorDirectory, config.ManifestFileName),
+	}
+	for _, component := range spec.Components {
+		owned = append(owned, projectComponents[component].path)
+	}
+	if spec.isLibrary() {
+		owned = append(owned, libraryFilePaths(spec.Module)...)
+	}
+	var conflicts []string
+	for _, path := range owned {
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			entries, err := os.ReadDir(path)
+			if err != nil {
+				return err
+			}
+			if len(entries) == 0 {
+				continue
+			}
+		}
+		conflicts = append(conflicts, path)
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("the directory already has %s, use --forceCreate to overwrite them", strings.Join(conflicts, ", "))
+	}
 	return nil
 }
 
-// todo after init, possibility to add new config keys and config files with separate structures
-func initializeConfig(appName, appType, configLibName string) error {
-	// create config directory structure
-	const (
-		configFileDirectory     = "config"
-		configSourceDirectory   = "pkg/infra/config" // todo delegate creating this structure to separate module
-		initialConfigFileName   = "application.yaml"
-		initialConfigSourceName = "application.go"
-	)
-	initialConfigurationFileContents := fmt.Sprintf("ApplicationName: %s", appName)
-	initialConfigurationSourceContents := `package config
+func entrypointExists(name string) bool {
+	_, err := os.Stat(fmt.Sprintf("cmd/%s/main.go", name))
+	return err == nil
+}
 
-import (
-	"github.com/spf13/viper"
-)
+func createDirectory(applicationName string, force bool) error {
+	if force {
+		err := os.RemoveAll(applicationName)
+		if err != nil {
+			return fmt.Errorf("failed to remove existing directory")
+		}
+	}
+	err := os.Mkdir(applicationName, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create dir for project: %w", err)
+	}
+	return nil
+}
 
-type ApplicationConfig struct {
-	ApplicationName string ` + "`yaml:\"ApplicationName\"`" + `
+func initializeProject(module string) error {
+	return writeGoMod(module)
 }
 
-func NewApplicationConfig() (*ApplicationConfig, error) {
-	viper.SetConfigFile("config/application.yaml")
-	err := viper.ReadInConfig()
+// projectModulePath returns the module path declared in the go.mod of the project, the prefix of the
+// imports generators add between the project's packages
+func projectModulePath() (string, error) {
+	file, err := readGoMod()
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if file.Module == nil {
+		return "", fmt.Errorf("go.mod doesn't declare the module path")
 	}
-	config := &ApplicationConfig{}
-	err = viper.Unmarshal(config)
+	return file.Module.Mod.Path, nil
+}
+
+// todo Create dir for middleware
+func initializeEntrypoint(name string, appType string, framework string, module string) error {
+	application, err := resolveApplicationType(appType)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	return config, nil
-}`
-	cmd := exec.Command("go", "get", configLibName)
-	err := cmd.Run()
+	entrypoint := entrypointContext{Name: name, Module: module, Framework: framework}
+	err = requireModules(application.Dependencies(entrypoint)...)
 	if err != nil {
 		return err
 	}
-	err = os.Mkdir(configFileDirectory, os.ModePerm)
+	files, err := application.Files(entrypoint)
 	if err != nil {
 		return err
 	}
-	err = os.MkdirAll(configSourceDirectory, os.ModePerm)
+	for path, contents := range files {
+		err = os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		if err != nil {
+			return err
+		}
+		// some templates end in a newline and some don't, the files end in exactly one
+		err = os.WriteFile(path, []byte(strings.TrimRight(contents, "\n")+"\n"), 0644)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// todo after init, possibility to add new config keys and config files with separate structures
+func initializeConfig(appName, module string, appTypes []string, fileFormat configFormat, backend configBackend) error {
+	err := initializeConfigDirectories()
 	if err != nil {
 		return err
 	}
-	yaml, err := os.Create(fmt.Sprintf("%s/%s", configFileDirectory, initialConfigFileName))
+	err = createCategory(initialCategory, fileFormat, backend)
 	if err != nil {
 		return err
 	}
-	defer yaml.Close()
-	_, err = fmt.Fprintln(yaml, initialConfigurationFileContents)
+	err = createPropertyOnCategory(initialCategory, "", "ApplicationName", "string", propertyOptions{})
 	if err != nil {
 		return err
 	}
-	source, err := os.Create(fmt.Sprintf("%s/%s", configSourceDirectory, initialConfigSourceName))
+	err = fileFormat.SetProperty(configFilePath(initialCategory, fileFormat), []string{"ApplicationName"}, appName)
 	if err != nil {
 		return err
 	}
-	defer source.Close()
-	_, err = fmt.Fprintln(source, initialConfigurationSourceContents)
+	for _, appType := range appTypes {
+		err = loadConfigInEntrypoint(module, appType)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// initializeConfigDirectories creates the directories of the config files and of the config package
+func initializeConfigDirectories() error {
+	// create config directory structure
+	const (
+		configFileDirectory   = config.ConfigFileDirectory
+		configSourceDirectory = config.ConfigSourceDirectory // todo delegate creating this structure to separate module
+	)
+	err := os.MkdirAll(configFileDirectory, os.ModePerm)
 	if err != nil {
 		return err
 	}
+	return os.MkdirAll(configSourceDirectory, os.ModePerm)
+}
 
-	// alter main.go code
+// loadConfigInEntrypoint makes the entrypoint's main load the config and greet with the application's name
+func loadConfigInEntrypoint(module string, name string) error {
 	fset := token.NewFileSet()
-	node, err := parser.ParseFile(fset, fmt.Sprintf("cmd/%s/main.go", appType), nil, parser.AllErrors|parser.ParseComments)
+	node, err := parser.ParseFile(fset, fmt.Sprintf("cmd/%s/main.go", name), nil, parser.AllErrors|parser.ParseComments)
 	if err != nil {
 		return err
 	}
+	if loadsConfig(node) {
+		return nil
+	}
 	funcName := "main"
 	for _, decl := range node.Decls {
 		if f, ok := decl.(*ast.FuncDecl); ok && f.Name.Name == funcName {
 			configAssignmentStatement := &ast.AssignStmt{
 				Lhs: []ast.Expr{
-					&ast.Ident{Name: "applicationConfig"},
+					&ast.Ident{Name: "configuration"},
 					&ast.Ident{Name: "err"},
 				},
 				Tok: token.DEFINE,
@@ -235,7 +498,7 @@ func NewApplicationConfig() (*ApplicationConfig, error) {
 					&ast.CallExpr{
 						Fun: &ast.SelectorExpr{
 							X:   &ast.Ident{Name: "config"},
-							Sel: &ast.Ident{Name: "NewApplicationConfig"},
+							Sel: &ast.Ident{Name: "Load"},
 						},
 					},
 				},
@@ -281,7 +544,10 @@ func NewApplicationConfig() (*ApplicationConfig, error) {
 											Value: fmt.Sprintf("\"%s %s\"", previousMessage, "Welcome to %s!"),
 										},
 										&ast.SelectorExpr{
-											X:   &ast.Ident{Name: "applicationConfig"},
+											X: &ast.SelectorExpr{
+												X:   &ast.Ident{Name: "configuration"},
+												Sel: &ast.Ident{Name: "Application"},
+											},
 											Sel: &ast.Ident{Name: "ApplicationName"},
 										},
 									}
@@ -295,12 +561,12 @@ func NewApplicationConfig() (*ApplicationConfig, error) {
 
 			f.Body.List = append([]ast.Stmt{configAssignmentStatement, errorCheckStatement}, f.Body.List...)
 			astutil.AddImport(fset, node, "log")
-			astutil.AddImport(fset, node, fmt.Sprintf("%s/%s", appName, configSourceDirectory))
+			astutil.AddImport(fset, node, fmt.Sprintf("%s/%s", module, config.ConfigSourceDirectory))
 			ast.SortImports(fset, node)
 		}
 	}
 
-	main, err := os.Create(fmt.Sprintf("cmd/%s/main.go", appType))
+	main, err := os.Create(fmt.Sprintf("cmd/%s/main.go", name))
 	if err != nil {
 		return err
 	}
@@ -312,16 +578,43 @@ func NewApplicationConfig() (*ApplicationConfig, error) {
 	return nil
 }
 
-func initializeGeneratorData() error {
-	err := os.Mkdir("generator", os.ModePerm)
+// loadsConfig reports whether the entrypoint calls config.Load already, like the worker entrypoints do
+func loadsConfig(node *ast.File) bool {
+	var found bool
+	ast.Inspect(node, func(n ast.Node) bool {
+		if selector, ok := n.(*ast.SelectorExpr); ok && selector.Sel.Name == "Load" {
+			if ident, ok := selector.X.(*ast.Ident); ok && ident.Name == "config" {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+// initializeGeneratorData writes the manifest, completed with the config choices
+func initializeGeneratorData(manifest *projectManifest, fileFormat configFormat, backend configBackend) error {
+	err := os.MkdirAll(config.GeneratorDirectory, os.ModePerm)
 	if err != nil {
 		return err
 	}
-	return nil
+	manifest.ConfigFormat = fileFormat.Name()
+	manifest.ConfigLibrary = backend.Name()
+	return writeManifest(manifest)
 }
 
 func init() {
 	initCmd.Flags().BoolVarP(&forceCreate, "forceCreate", "f", false, "This flag makes it possible to create new, clean project, even if directory with the same name already exists")
-	initCmd.Flags().StringVarP(&applicationType, "applicationType", "t", applicationTypeCli, "Decide whether first app's entrypoint should be cli or api") // todo implement api
+	initCmd.Flags().StringVarP(&initApplicationType, "applicationType", "t", applicationTypeCli, fmt.Sprintf("Type of the first app's entrypoint (%s), or %s for a project without entrypoints", strings.Join(applicationTypeNames(), ", "), projectTypeLibrary))
+	initCmd.Flags().StringVar(&projectFormat, "format", config.DefaultConfigFormat, fmt.Sprintf("Format of the project's config files (%s)", strings.Join(configFormatNames(), ", ")))
+	initCmd.Flags().StringVar(&configLibrary, "configLibrary", config.DefaultConfigLibrary, fmt.Sprintf("Library the generated code loads the config with (%s)", strings.Join(configBackendNames(), ", ")))
+	initCmd.Flags().BoolVarP(&interactiveInit, "interactive", "i", false, "Ask for the project's choices even when not run in a terminal")
+	initCmd.Flags().BoolVar(&inPlace, "in-place", false, "Generate the project in the current directory, adopting its go.mod. Same as init .")
+	initCmd.Flags().StringVar(&modulePath, "module", "", "Go module path of the project, defaults to its name. The name defaults to the path's last element")
+	initCmd.Flags().BoolVar(&useWorkspace, "workspace", false, "Add the project to the nearest go.work above the current directory")
+	initCmd.Flags().BoolVar(&tidyModule, "tidy", false, "Run go mod tidy in the generated project, which needs the network or a complete module cache")
+	initCmd.Flags().StringVar(&specPath, "spec", "", "Generate the project declared in the yaml spec file")
+	initCmd.MarkFlagFilename("spec", "yaml", "yml")
+	initCmd.RegisterFlagCompletionFunc("applicationType", completeProjectTypes)
 	rootCmd.AddCommand(initCmd)
 }
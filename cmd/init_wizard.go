@@ -0,0 +1,266 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+	"gopkg.in/yaml.v3"
+)
+
+// specFileName is the name of the spec the wizard saves in the generated project, so init --spec can replay it
+const specFileName = "project.yaml"
+
+// wizard asks for the choices of init one by one, repeating a question until the answer is valid
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// isTerminal reports whether the file is a terminal rather than a pipe or a regular file
+func isTerminal(file *os.File) bool {
+	return term.IsTerminal(int(file.Fd()))
+}
+
+func (w *wizard) ask(question string, defaultAnswer string, validate func(string) error) (string, error) {
+	for {
+		if defaultAnswer != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", question, defaultAnswer)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", question)
+		}
+		line, err := w.in.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return "", fmt.Errorf("failed to read the answer: %w", err)
+		}
+		answer := strings.TrimSpace(line)
+		if answer == "" {
+			answer = defaultAnswer
+		}
+		if validate != nil {
+			if problem := validate(answer); problem != nil {
+				fmt.Fprintf(w.out, "  %v\n", problem)
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+func (w *wizard) choose(question string, options []string, defaultAnswer string) (string, error) {
+	return w.ask(fmt.Sprintf("%s (%s)", question, strings.Join(options, ", ")), defaultAnswer, func(answer string) error {
+		for _, option := range options {
+			if answer == option {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of: %s", strings.Join(options, ", "))
+	})
+}
+
+// chooseMany accepts a comma separated list of the options, or nothing
+func (w *wizard) chooseMany(question string, options []string) ([]string, error) {
+	var chosen []string
+	_, err := w.ask(fmt.Sprintf("%s, comma separated (%s)", question, strings.Join(options, ", ")), "", func(answer string) error {
+		chosen = nil
+		for _, item := range strings.Split(answer, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			valid := false
+			for _, option := range options {
+				valid = valid || item == option
+			}
+			if !valid {
+				return fmt.Errorf("%q isn't one of: %s", item, strings.Join(options, ", "))
+			}
+			chosen = append(chosen, item)
+		}
+		return nil
+	})
+	return chosen, err
+}
+
+func (w *wizard) confirm(question string, defaultAnswer bool) (bool, error) {
+	hint := "y/N"
+	if defaultAnswer {
+		hint = "Y/n"
+	}
+	answer, err := w.ask(fmt.Sprintf("%s [%s]", question, hint), "", func(answer string) error {
+		switch strings.ToLower(answer) {
+		case "", "y", "yes", "n", "no":
+			return nil
+		}
+		return fmt.Errorf("answer y or n")
+	})
+	if err != nil {
+		return false, err
+	}
+	if answer == "" {
+		return defaultAnswer, nil
+	}
+	return strings.HasPrefix(strings.ToLower(answer), "y"), nil
+}
+
+func required(what string) func(string) error {
+	return func(answer string) error {
+		if answer == "" {
+			return fmt.Errorf("the %s is required", what)
+		}
+		if strings.ContainsAny(answer, " \t") {
+			return fmt.Errorf("the %s can't contain spaces", what)
+		}
+		return nil
+	}
+}
+
+// runWizard asks for the project spec. It returns nil when the user doesn't confirm the summary.
+func (w *wizard) runWizard() (*projectSpec, error) {
+	spec := &projectSpec{Template: defaultTemplatePack}
+	var err error
+	spec.Name, err = w.ask("Project name", "", func(answer string) error {
+		if err := required("project name")(answer); err != nil {
+			return err
+		}
+		if strings.ContainsAny(answer, `/\`) {
+			return fmt.Errorf("the project name is a directory name and can't contain slashes")
+		}
+		if _, err := os.Stat(answer); err == nil {
+			return fmt.Errorf("%s exists already", answer)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	spec.Module, err = w.ask("Module path", spec.Name, required("module path"))
+	if err != nil {
+		return nil, err
+	}
+	application, err := w.choose("Application type", append(applicationTypeNames(), projectTypeLibrary), applicationTypeCli)
+	if err != nil {
+		return nil, err
+	}
+	spec.Applications = []string{application}
+	if application == applicationTypeApi {
+		spec.HTTP, err = w.choose("HTTP framework", []string{"gin", "echo", "std"}, "gin")
+		if err != nil {
+			return nil, err
+		}
+	}
+	spec.Config.Library, err = w.choose("Config library", configBackendNames(), "viper")
+	if err != nil {
+		return nil, err
+	}
+	backend, err := resolveConfigBackend(spec.Config.Library)
+	if err != nil {
+		return nil, err
+	}
+	spec.Config.Format, err = w.choose("Config file format", backend.Formats(), backend.Formats()[0])
+	if err != nil {
+		return nil, err
+	}
+	spec.Components, err = w.chooseMany("Components", projectComponentNames())
+	if err != nil {
+		return nil, err
+	}
+	err = w.askCategories(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := yaml.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Fprintf(w.out, "\nThe project:\n\n%s\n", summary)
+	confirmed, err := w.confirm("Generate it?", true)
+	if err != nil || !confirmed {
+		return nil, err
+	}
+	return spec, nil
+}
+
+func (w *wizard) askCategories(spec *projectSpec) error {
+	taken := map[string]bool{initialCategory: true}
+	for _, component := range spec.Components {
+		taken[projectComponents[component].category] = true
+	}
+	for {
+		more, err := w.confirm("Add a config category?", false)
+		if err != nil || !more {
+			return err
+		}
+		name, err := w.ask("  Category name", "", func(answer string) error {
+			answer = strings.ToLower(answer)
+			if err := required("category name")(answer); err != nil {
+				return err
+			}
+			if taken[answer] {
+				return fmt.Errorf("the category %s exists already", answer)
+			}
+			return checkCategoryName(answer)
+		})
+		if err != nil {
+			return err
+		}
+		category := categorySpec{Name: strings.ToLower(name)}
+		taken[category.Name] = true
+		properties := map[string]bool{}
+		for {
+			more, err := w.confirm(fmt.Sprintf("  Add a property to %s?", category.Name), true)
+			if err != nil {
+				return err
+			}
+			if !more {
+				break
+			}
+			property := propertySpec{}
+			property.Name, err = w.ask("    Property name", "", func(answer string) error {
+				if err := required("property name")(answer); err != nil {
+					return err
+				}
+				if properties[strings.ToLower(answer)] {
+					return fmt.Errorf("the property %s exists already", answer)
+				}
+				return checkConfigName("property", strings.ToLower(answer))
+			})
+			if err != nil {
+				return err
+			}
+			properties[strings.ToLower(property.Name)] = true
+			property.Type, err = w.ask("    Type", "string", func(answer string) error {
+				if err := required("type")(answer); err != nil {
+					return err
+				}
+				return checkTypeName(answer)
+			})
+			if err != nil {
+				return err
+			}
+			property.Default, err = w.ask("    Default value", "", nil)
+			if err != nil {
+				return err
+			}
+			property.Required, err = w.confirm("    Required?", false)
+			if err != nil {
+				return err
+			}
+			category.Properties = append(category.Properties, property)
+		}
+		spec.Config.Categories = append(spec.Config.Categories, category)
+	}
+}
+
+// saveSpec writes the spec as the project's spec file. It's called from the generated project's directory.
+func saveSpec(spec *projectSpec) error {
+	contents, err := yaml.Marshal(spec)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(specFileName, contents, 0644)
+}
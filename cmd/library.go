@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// projectTypeLibrary is the type of projects without entrypoints. init accepts it in place of the
+// application types, alone.
+const projectTypeLibrary = "library"
+
+// libraryFiles are the files of a library project by their path, the root package named {{.Package}}
+var libraryFiles = map[string]*template.Template{
+	"doc.go": template.Must(template.New("doc").Parse(`// Package {{.Package}} is the root package of {{.Module}}. Its exported API is built on the packages of
+// internal/, which other modules can't import.
+package {{.Package}}
+`)),
+	"{{.Package}}.go": template.Must(template.New("api").Parse(`package {{.Package}}
+
+import (
+	"{{.Module}}/internal/greeting"
+)
+
+// Greet returns the greeting of the name
+func Greet(name string) string {
+	return greeting.Format(name)
+}
+`)),
+	"example_test.go": template.Must(template.New("example").Parse(`package {{.Package}}_test
+
+import (
+	"fmt"
+
+	"{{.Module}}"
+)
+
+func ExampleGreet() {
+	fmt.Println({{.Package}}.Greet("world"))
+	// Output: Hello world!
+}
+`)),
+	"internal/greeting/greeting.go": template.Must(template.New("internal").Parse(`// Package greeting implements the greetings of the library, out of reach of its users
+package greeting
+
+import (
+	"fmt"
+)
+
+func Format(name string) string {
+	return fmt.Sprintf("Hello %s!", name)
+}
+`)),
+}
+
+// libraryPackageName turns the last element of the module path into a package name: go-client is goclient
+func libraryPackageName(module string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return -1
+	}, moduleDirectoryName(module))
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "lib" + name
+	}
+	return name
+}
+
+// libraryFilePaths returns the paths of the library's files in the project
+func libraryFilePaths(module string) []string {
+	paths := make([]string, 0, len(libraryFiles))
+	for path := range libraryFiles {
+		paths = append(paths, strings.ReplaceAll(path, "{{.Package}}", libraryPackageName(module)))
+	}
+	return paths
+}
+
+// initializeLibrary writes the root package of the library, its example test and its internal package
+func initializeLibrary(module string) error {
+	data := map[string]string{"Module": module, "Package": libraryPackageName(module)}
+	for path, source := range libraryFiles {
+		var code bytes.Buffer
+		err := source.Execute(&code, data)
+		if err != nil {
+			return err
+		}
+		formattedCode, err := format.Source(code.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to format code: %w", err)
+		}
+		err = writeProjectFile(strings.ReplaceAll(path, "{{.Package}}", data["Package"]), formattedCode)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isLibrary reports whether the spec declares a library rather than applications
+func (s *projectSpec) isLibrary() bool {
+	return len(s.Applications) == 1 && s.Applications[0] == projectTypeLibrary
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"template/config"
+)
+
+// projectManifest holds the choices made when the project was generated, so that
+// later commands can generate code consistent with them
+type projectManifest struct {
+	// ProjectType is library for projects without entrypoints, empty for applications
+	ProjectType    string               `json:"projectType,omitempty"`
+	ConfigFormat   string               `json:"configFormat"`
+	ConfigLibrary  string               `json:"configLibrary"`
+	FlagCategories []string             `json:"flagCategories,omitempty"`
+	Entrypoints    []manifestEntrypoint `json:"entrypoints,omitempty"`
+	// Database is the driver the SQL repositories are generated for
+	Database string `json:"database,omitempty"`
+}
+
+// manifestEntrypoint is a binary of the project, built from cmd/<name>
+type manifestEntrypoint struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Framework is the HTTP framework of api entrypoints
+	Framework string `json:"framework,omitempty"`
+}
+
+func newManifestEntrypoint(name string, appType string, framework string) manifestEntrypoint {
+	entrypoint := manifestEntrypoint{Name: name, Type: appType}
+	if appType == applicationTypeApi {
+		entrypoint.Framework = framework
+	}
+	return entrypoint
+}
+
+func manifestPath() string {
+	return filepath.Join(config.GeneratorDirectory, config.ManifestFileName)
+}
+
+// readManifest returns the manifest of the project in the working directory. Projects
+// generated before the manifest existed get the defaults.
+func readManifest() (*projectManifest, error) {
+	manifest := &projectManifest{ConfigFormat: config.DefaultConfigFormat, ConfigLibrary: config.DefaultConfigLibrary}
+	contents, err := os.ReadFile(manifestPath())
+	if os.IsNotExist(err) {
+		return manifest, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(contents, manifest)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+func writeManifest(manifest *projectManifest) error {
+	contents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(config.GeneratorDirectory, os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(), append(contents, '\n'), 0644)
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	migrationsDirectory = "migrations"
+	migrateEntrypoint   = "migrate"
+)
+
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_.+\.(up|down)\.sql$`)
+
+// migrationsPackage embeds the numbered migrations of the project and applies them
+var migrationsPackage = template.Must(template.New("migrations").Parse(`// Package migrations holds the numbered SQL migrations of the database, NNNN_name.up.sql and the
+// NNNN_name.down.sql reverting it, and applies them. The applied versions are recorded in the
+// schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+var filePattern = regexp.MustCompile(` + "`" + `^(\d+)_.+\.(up|down)\.sql$` + "`" + `)
+
+type migration struct {
+	version  int64
+	up, down string
+}
+
+// Up applies the migrations not applied yet, in the order of their versions, each in a transaction
+func Up(ctx context.Context, db *sql.DB) error {
+	migrations, applied, err := load(ctx, db)
+	if err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		if applied[migration.version] {
+			continue
+		}
+		err = run(ctx, db, migration.up, fmt.Sprintf("INSERT INTO schema_migrations (version) VALUES (%d)", migration.version))
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d: %w", migration.version, err)
+		}
+	}
+	return nil
+}
+
+// Down reverts the latest applied migration
+func Down(ctx context.Context, db *sql.DB) error {
+	migrations, applied, err := load(ctx, db)
+	if err != nil {
+		return err
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		migration := migrations[i]
+		if !applied[migration.version] {
+			continue
+		}
+		err = run(ctx, db, migration.down, fmt.Sprintf("DELETE FROM schema_migrations WHERE version = %d", migration.version))
+		if err != nil {
+			return fmt.Errorf("failed to revert migration %d: %w", migration.version, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// load returns the migrations sorted by version and the versions applied to the database
+func load(ctx context.Context, db *sql.DB) ([]migration, map[int64]bool, error) {
+	byVersion := map[int64]*migration{}
+	entries, err := fs.ReadDir(files, ".")
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, entry := range entries {
+		match := filePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, nil, err
+		}
+		contents, err := files.ReadFile(entry.Name())
+		if err != nil {
+			return nil, nil, err
+		}
+		if byVersion[version] == nil {
+			byVersion[version] = &migration{version: version}
+		}
+		if match[2] == "up" {
+			byVersion[version].up = string(contents)
+		} else {
+			byVersion[version].down = string(contents)
+		}
+	}
+	migrations := make([]migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	_, err = db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT PRIMARY KEY)")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+	rows, err := db.QueryContext(ctx, "SELECT version FROM schema_migrations")
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	applied := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, nil, err
+		}
+		applied[version] = true
+	}
+	return migrations, applied, rows.Err()
+}
+
+func run(ctx context.Context, db *sql.DB, statement string, record string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.ExecContext(ctx, statement)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, record)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+`))
+
+// migrateMain applies the migrations to the configured database, or reverts the latest one with migrate down
+var migrateMain = template.Must(template.New("migrate").Parse(`package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	_ "{{.DriverModule}}"
+
+	"{{.Module}}/migrations"
+	"{{.Module}}/pkg/infra/config"
+	"{{.Module}}/pkg/infra/database"
+)
+
+// migrate applies the pending migrations of the database category's database. migrate down reverts the
+// latest applied migration.
+func main() {
+	configuration, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to read configuration file: %v", err)
+	}
+	db, err := database.Open(configuration.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx := context.Background()
+	if len(os.Args) > 1 && os.Args[1] == "down" {
+		err = migrations.Down(ctx, db)
+	} else {
+		err = migrations.Up(ctx, db)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+`))
+
+// initializeMigrations writes the migrations package, and the migrate entrypoint applying them when asked
+// for, unless the project has them
+func initializeMigrations(driverName string, module string, withEntrypoint bool) error {
+	path := filepath.Join(migrationsDirectory, "migrations.go")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		err = writeTemplate(path, migrationsPackage, nil)
+		if err != nil {
+			return err
+		}
+	}
+	if !withEntrypoint || entrypointExists(migrateEntrypoint) {
+		return nil
+	}
+	return writeTemplate(entrypointContext{Name: migrateEntrypoint}.mainPath(), migrateMain, map[string]string{
+		"Module":       module,
+		"DriverModule": sqlDrivers[driverName].module,
+	})
+}
+
+// nextMigrationVersion returns the version following the latest migration of the project
+func nextMigrationVersion() (int, error) {
+	entries, err := os.ReadDir(migrationsDirectory)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	latest := 0
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, err
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest + 1, nil
+}
+
+// writeMigration writes the up and down files of the next migration
+func writeMigration(name string, up string, down string) error {
+	version, err := nextMigrationVersion()
+	if err != nil {
+		return err
+	}
+	prefix := filepath.Join(migrationsDirectory, fmt.Sprintf("%04d_%s", version, name))
+	err = writeProjectFile(prefix+".up.sql", []byte(up))
+	if err != nil {
+		return err
+	}
+	return writeProjectFile(prefix+".down.sql", []byte(down))
+}
+
+// writeTableMigrations writes the migration creating the table of the entity
+func writeTableMigrations(entity string, driver sqlDriver, columns []sqlColumn) error {
+	table := tableName(entityTypeName(entity))
+	definitions := []string{fmt.Sprintf("%s %s PRIMARY KEY", driver.quoteIdentifier("id"), driver.columnTypes[sqlColumnIdentifier])}
+	for _, column := range columns {
+		definition := fmt.Sprintf("%s %s", driver.quoteIdentifier(column.Name), column.Type)
+		if !column.Null {
+			definition += " NOT NULL"
+		}
+		definitions = append(definitions, definition)
+	}
+	up := fmt.Sprintf("CREATE TABLE %s (\n\t%s\n);\n", driver.quoteIdentifier(table), strings.Join(definitions, ",\n\t"))
+	down := fmt.Sprintf("DROP TABLE %s;\n", driver.quoteIdentifier(table))
+	return writeMigration("create_"+table, up, down)
+}
+
+// writeColumnMigration writes the migration adding the column to the table of the entity. The column is
+// nullable, the rows of the table having no value for it.
+func writeColumnMigration(entity string, driver sqlDriver, column sqlColumn) error {
+	table := tableName(entityTypeName(entity))
+	up := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;\n", driver.quoteIdentifier(table), driver.quoteIdentifier(column.Name), column.Type)
+	down := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", driver.quoteIdentifier(table), driver.quoteIdentifier(column.Name))
+	return writeMigration(fmt.Sprintf("add_%s_%s", table, column.Name), up, down)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"template/config"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// projectDir is the directory the commands run in instead of the working directory
+var projectDir string
+
+// findProjectRoot walks up from the directory to the nearest root of a generated project, the directory
+// holding the manifest. Projects generated before the manifest existed are recognized by their go.mod next
+// to the config package, a go.mod alone being the root of any module. It returns an empty path when there's none.
+func findProjectRoot(directory string) (string, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return "", err
+	}
+	for {
+		if isProjectRoot(directory) {
+			return directory, nil
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", nil
+		}
+		directory = parent
+	}
+}
+
+func isProjectRoot(directory string) bool {
+	if _, err := os.Stat(filepath.Join(directory, manifestPath())); err == nil {
+		return true
+	}
+	for _, marker := range []string{goModFileName, config.ConfigSourceDirectory} {
+		if _, err := os.Stat(filepath.Join(directory, marker)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// enterProjectDirectory runs the command in the directory given with --project-dir, if any. The file and
+// directory flags of the command given relative paths keep pointing where they did.
+func enterProjectDirectory(cmd *cobra.Command, args []string) error {
+	if projectDir == "" {
+		return nil
+	}
+	err := absolutizePathFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return os.Chdir(projectDir)
+}
+
+// enterProjectRoot makes the root of the project holding the working directory, or the directory given with
+// --project-dir, the working directory of the command, the generator's paths being relative to it. The file
+// and directory flags of the command given relative paths keep pointing where they did.
+func enterProjectRoot(cmd *cobra.Command, args []string) error {
+	start, err := filepath.Abs(projectDir)
+	if err != nil {
+		return err
+	}
+	root, err := findProjectRoot(start)
+	if err != nil {
+		return err
+	}
+	if root == "" {
+		return fmt.Errorf("not inside a generated project (use --project-dir)")
+	}
+	err = absolutizePathFlags(cmd)
+	if err != nil {
+		return err
+	}
+	return os.Chdir(root)
+}
+
+// absolutizePathFlags resolves the relative paths given to the flags marked as file or directory names
+func absolutizePathFlags(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().Visit(func(flag *pflag.Flag) {
+		_, isFile := flag.Annotations[cobra.BashCompFilenameExt]
+		_, isDirectory := flag.Annotations[cobra.BashCompSubdirsInDir]
+		value := flag.Value.String()
+		if err != nil || (!isFile && !isDirectory) || value == "" || value == "-" || filepath.IsAbs(value) {
+			return
+		}
+		var path string
+		path, err = filepath.Abs(value)
+		if err == nil {
+			err = flag.Value.Set(path)
+		}
+	})
+	return err
+}
@@ -18,6 +18,7 @@ var rootCmd = &cobra.Command{
 It makes creating
 new projects faster, easier and better through code generation
 and code standarization.`,
+	PersistentPreRunE: enterProjectDirectory,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -30,4 +31,6 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&projectDir, "project-dir", "", "Directory the command runs in instead of the working directory. The project commands act on the project it belongs to")
+	rootCmd.MarkPersistentFlagDirname("project-dir")
 }
@@ -0,0 +1,303 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"template/config"
+
+	"golang.org/x/mod/module"
+	"gopkg.in/yaml.v3"
+)
+
+const defaultTemplatePack = "default"
+
+var httpFrameworks = map[string]bool{"gin": true, "echo": true, "std": true}
+
+// projectSpec declares everything init generates, so that a project can be bootstrapped from a checked-in file
+type projectSpec struct {
+	Name         string     `yaml:"name,omitempty"`
+	Module       string     `yaml:"module,omitempty"`
+	Applications []string   `yaml:"applications,omitempty"`
+	HTTP         string     `yaml:"http,omitempty"`
+	Config       configSpec `yaml:"config,omitempty"`
+	Components   []string   `yaml:"components,omitempty"`
+	Template     string     `yaml:"template,omitempty"`
+}
+
+type configSpec struct {
+	Format     string         `yaml:"format,omitempty"`
+	Library    string         `yaml:"library,omitempty"`
+	Categories []categorySpec `yaml:"categories,omitempty"`
+}
+
+type categorySpec struct {
+	Name       string         `yaml:"name,omitempty"`
+	Format     string         `yaml:"format,omitempty"`
+	Source     string         `yaml:"source,omitempty"`
+	Watch      bool           `yaml:"watch,omitempty"`
+	Flags      bool           `yaml:"flags,omitempty"`
+	Properties []propertySpec `yaml:"properties,omitempty"`
+}
+
+type propertySpec struct {
+	Name        string `yaml:"name,omitempty"`
+	Type        string `yaml:"type,omitempty"`
+	Section     string `yaml:"section,omitempty"`
+	Default     string `yaml:"default,omitempty"`
+	Required    bool   `yaml:"required,omitempty"`
+	Secret      bool   `yaml:"secret,omitempty"`
+	Flag        bool   `yaml:"flag,omitempty"`
+	Description string `yaml:"description,omitempty"`
+}
+
+// readProjectSpec reads the spec file, rejecting keys the spec doesn't know
+func readProjectSpec(path string) (*projectSpec, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	spec := &projectSpec{}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err = decoder.Decode(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse project spec %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// complete fills what the spec leaves out with the defaults of init
+func (s *projectSpec) complete() error {
+	if s.Name == "" && s.Module != "" {
+		s.Name = moduleDirectoryName(s.Module)
+	}
+	if s.Module == "" {
+		s.Module = s.Name
+	}
+	if len(s.Applications) == 0 {
+		s.Applications = []string{applicationTypeCli}
+	}
+	if s.HTTP == "" {
+		s.HTTP = "gin"
+	}
+	if s.Template == "" {
+		s.Template = defaultTemplatePack
+	}
+	if s.Config.Library == "" {
+		s.Config.Library = config.DefaultConfigLibrary
+	}
+	if s.Config.Format == "" {
+		backend, err := resolveConfigBackend(s.Config.Library)
+		if err != nil {
+			return err
+		}
+		s.Config.Format = backend.Formats()[0]
+	}
+	return nil
+}
+
+// moduleDirectoryName returns the last element of the module path, skipping a major version suffix:
+// github.com/org/app/v2 is generated in app
+func moduleDirectoryName(module string) string {
+	elements := strings.Split(strings.TrimSuffix(module, "/"), "/")
+	last := elements[len(elements)-1]
+	if len(elements) > 1 && len(last) > 1 && last[0] == 'v' && strings.Trim(last[1:], "0123456789") == "" {
+		last = elements[len(elements)-2]
+	}
+	return last
+}
+
+// validate checks the choices of the spec before anything is generated
+func (s *projectSpec) validate() error {
+	if s.Name == "" {
+		return fmt.Errorf("the project's name is missing")
+	}
+	if err := module.CheckImportPath(s.Module); err != nil {
+		return fmt.Errorf("invalid module path: %w", err)
+	}
+	seen := map[string]bool{}
+	for _, application := range s.Applications {
+		if application == projectTypeLibrary {
+			if len(s.Applications) > 1 {
+				return fmt.Errorf("a %s has no entrypoints, it can't be declared with application types", projectTypeLibrary)
+			}
+			continue
+		}
+		if _, ok := applicationTypes[application]; !ok {
+			// unlike add entrypoint, init generates libraries as well
+			return fmt.Errorf("unsupported application type %q, expected one of: %s", application, strings.Join(append(applicationTypeNames(), projectTypeLibrary), ", "))
+		}
+		if seen[application] {
+			return fmt.Errorf("application type %s is declared twice", application)
+		}
+		seen[application] = true
+	}
+	if !httpFrameworks[s.HTTP] {
+		return fmt.Errorf("unsupported HTTP framework %q, expected gin, echo or std", s.HTTP)
+	}
+	if s.Template != defaultTemplatePack {
+		return fmt.Errorf("unsupported template pack %q, the only pack is %s", s.Template, defaultTemplatePack)
+	}
+	backend, err := resolveConfigBackend(s.Config.Library)
+	if err != nil {
+		return err
+	}
+	fileFormat, err := resolveConfigFormat(s.Config.Format)
+	if err != nil {
+		return err
+	}
+	err = checkBackendFormat(backend, fileFormat)
+	if err != nil {
+		return err
+	}
+	categories := map[string]bool{}
+	for _, name := range s.Components {
+		component, ok := projectComponents[name]
+		if !ok {
+			return fmt.Errorf("unsupported component %q, expected one of: %s", name, strings.Join(projectComponentNames(), ", "))
+		}
+		categories[component.category] = true
+	}
+	for _, category := range s.Config.Categories {
+		name := strings.ToLower(category.Name)
+		if name == "" {
+			return fmt.Errorf("a category has no name")
+		}
+		if categories[name] {
+			return fmt.Errorf("category %s is declared twice or by a component", name)
+		}
+		categories[name] = true
+		err = checkCategoryName(name)
+		if err != nil {
+			return err
+		}
+		categoryFormat := fileFormat
+		if category.Format != "" {
+			categoryFormat, err = resolveConfigFormat(category.Format)
+			if err != nil {
+				return fmt.Errorf("category %s: %w", name, err)
+			}
+			err = checkBackendFormat(backend, categoryFormat)
+			if err != nil {
+				return fmt.Errorf("category %s: %w", name, err)
+			}
+		}
+		// the options are checked like the flags of config create, before anything is generated
+		err = checkCategoryOptions(backend, category.options())
+		if err != nil {
+			return fmt.Errorf("category %s: %w", name, err)
+		}
+		if name == initialCategory && !s.isLibrary() {
+			// the application category is created with the project, only watch and flags apply to it afterwards
+			if category.Source != "" {
+				return fmt.Errorf("category %s is created with the project, it can't have a source", name)
+			}
+			if category.Format != "" && category.Format != fileFormat.Name() {
+				return fmt.Errorf("category %s is created with the project, its format is the project's %s", name, fileFormat.Name())
+			}
+		}
+		properties := map[string]bool{}
+		for _, property := range category.Properties {
+			if property.Name == "" {
+				return fmt.Errorf("a property of category %s has no name", name)
+			}
+			err = property.check(backend, categoryFormat, category.Flags)
+			if err != nil {
+				return fmt.Errorf("property %s of category %s: %w", property.Name, name, err)
+			}
+			path := strings.Join(propertyPath(strings.ToLower(property.Section), strings.ToLower(property.Name)), ".")
+			if properties[path] {
+				return fmt.Errorf("property %s of category %s is declared twice", path, name)
+			}
+			properties[path] = true
+		}
+	}
+	return nil
+}
+
+// generateCategories creates the categories of the spec the way config create and config add do. The
+// application category of applications exists already, its loader and flags are added to it with its properties.
+func (s *projectSpec) generateCategories() error {
+	for _, category := range s.Config.Categories {
+		name := strings.ToLower(category.Name)
+		if name != initialCategory || s.isLibrary() {
+			err := createCategoryWithOptions(name, category.options())
+			if err != nil {
+				return fmt.Errorf("failed to create category %s: %w", name, err)
+			}
+		} else {
+			err := enableCategoryOptions(name, category.options())
+			if err != nil {
+				return fmt.Errorf("failed to configure category %s: %w", name, err)
+			}
+		}
+		for _, property := range category.Properties {
+			// like config add, the spec's names are case insensitive
+			property.Name = strings.ToLower(property.Name)
+			property.Section = strings.ToLower(property.Section)
+			err := property.add(name)
+			if err != nil {
+				return fmt.Errorf("failed to add property %s to category %s: %w", property.Name, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c categorySpec) options() categoryOptions {
+	return categoryOptions{
+		Format: c.Format,
+		Watch:  c.Watch,
+		Flags:  c.Flags,
+		Source: c.Source,
+	}
+}
+
+// check fails for the names, the type and the flag config add would refuse
+func (p propertySpec) check(backend configBackend, fileFormat configFormat, categoryFlags bool) error {
+	err := checkConfigName("property", strings.ToLower(p.Name))
+	if err != nil {
+		return err
+	}
+	if p.Section != "" {
+		err = checkConfigName("section", strings.ToLower(p.Section))
+		if err != nil {
+			return err
+		}
+	}
+	err = checkTypeName(p.typeName())
+	if err != nil {
+		return err
+	}
+	err = checkFormatType(backend, fileFormat, p.typeName())
+	if err != nil {
+		return err
+	}
+	if !p.Flag && !categoryFlags {
+		return nil
+	}
+	if backend.Name() != "viper" {
+		return fmt.Errorf("flags need the viper config library, the project uses %s", backend.Name())
+	}
+	return checkFlagType(p.typeName())
+}
+
+func (p propertySpec) typeName() string {
+	if p.Type == "" {
+		return "string"
+	}
+	return p.Type
+}
+
+func (p propertySpec) add(category string) error {
+	typeName := p.typeName()
+	options := propertyOptions{
+		Default:     p.Default,
+		Required:    p.Required,
+		Secret:      p.Secret,
+		Description: p.Description,
+	}
+	return addProperty(category, p.Section, p.Name, typeName, options, p.Flag)
+}
@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+const (
+	workerCategory    = "worker"
+	workerPackagePath = "pkg/worker/worker.go"
+)
+
+// workerProperties configure the worker package, they're shared by every worker entrypoint of the project
+var workerProperties = []propertySpec{
+	{Name: "concurrency", Type: "int", Default: "4", Description: "Number of goroutines running each continuous job"},
+	{Name: "pollInterval", Type: "time.Duration", Default: "1s", Description: "Wait before running a job again after it found no work or failed"},
+	{Name: "shutdownTimeout", Type: "time.Duration", Default: "30s", Description: "Time the running jobs get to finish when the worker stops"},
+	{Name: "healthAddress", Default: ":8081", Description: "Address of the health endpoint, empty to disable it"},
+}
+
+// workerEntrypoint runs the project's jobs with the worker package until it's interrupted
+var workerEntrypoint = template.Must(template.New("worker").Parse(`package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"{{.Module}}/pkg/infra/config"
+	"{{.Module}}/pkg/worker"
+)
+
+func main() {
+	configuration, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to read configuration file: %v", err)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	w := worker.New(configuration.Worker)
+	w.Handle(worker.JobFunc(process))
+	err = w.Schedule("@every 1m", worker.JobFunc(report))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("Hello world! Welcome to %s!", configuration.Application.ApplicationName)
+	err = w.Run(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// process handles one item of work, like a message of a queue. It returns worker.ErrNoWork when there's
+// nothing to do.
+func process(ctx context.Context) error {
+	return worker.ErrNoWork
+}
+
+// report runs every minute
+func report(ctx context.Context) error {
+	log.Println("report ran")
+	return nil
+}
+`))
+
+// workerPackage runs continuous and scheduled jobs, reports its health and shuts down gracefully
+var workerPackage = template.Must(template.New("workerPackage").Parse(`package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"{{.Module}}/pkg/infra/config"
+)
+
+// ErrNoWork is returned by a job finding nothing to do. The worker waits PollInterval before running it again.
+var ErrNoWork = errors.New("no work")
+
+// Job processes the work of a queue, a table or any other source, an item or a batch per run
+type Job interface {
+	Run(ctx context.Context) error
+}
+
+// JobFunc lets a function be a Job
+type JobFunc func(ctx context.Context) error
+
+func (f JobFunc) Run(ctx context.Context) error {
+	return f(ctx)
+}
+
+type scheduledJob struct {
+	schedule cron.Schedule
+	job      Job
+}
+
+// Worker runs continuous jobs with the configured concurrency and scheduled jobs on their schedule, until
+// its context is cancelled
+type Worker struct {
+	config    *config.WorkerConfig
+	jobs      []Job
+	scheduled []scheduledJob
+	ready     atomic.Bool
+}
+
+func New(cfg *config.WorkerConfig) *Worker {
+	return &Worker{config: cfg}
+}
+
+// Handle adds a job run again and again by Concurrency goroutines
+func (w *Worker) Handle(job Job) {
+	w.jobs = append(w.jobs, job)
+}
+
+// Schedule adds a job run on the schedule, in the cron format or as @every <duration>. A run still going
+// when the next one is due skips it.
+func (w *Worker) Schedule(schedule string, job Job) error {
+	parsed, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	w.scheduled = append(w.scheduled, scheduledJob{schedule: parsed, job: job})
+	return nil
+}
+
+// Run runs the jobs until the context is cancelled. The jobs running then get ShutdownTimeout to finish,
+// the context they're given is cancelled after it.
+func (w *Worker) Run(ctx context.Context) error {
+	jobCtx, cancelJobs := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelJobs()
+	health := w.serveHealth()
+
+	var running sync.WaitGroup
+	concurrency := max(w.config.Concurrency, 1)
+	for _, job := range w.jobs {
+		for i := 0; i < concurrency; i++ {
+			running.Add(1)
+			go func() {
+				defer running.Done()
+				w.consume(ctx, jobCtx, job)
+			}()
+		}
+	}
+	scheduler := cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DefaultLogger)))
+	for _, scheduled := range w.scheduled {
+		job := scheduled.job
+		scheduler.Schedule(scheduled.schedule, cron.FuncJob(func() {
+			err := job.Run(jobCtx)
+			if err != nil {
+				log.Printf("scheduled job failed: %v", err)
+			}
+		}))
+	}
+	scheduler.Start()
+	w.ready.Store(true)
+
+	<-ctx.Done()
+	w.ready.Store(false)
+	stopped := make(chan struct{})
+	go func() {
+		running.Wait()
+		<-scheduler.Stop().Done()
+		close(stopped)
+	}()
+	var err error
+	select {
+	case <-stopped:
+	case <-time.After(w.config.ShutdownTimeout):
+		cancelJobs()
+		err = fmt.Errorf("jobs still running %s after the worker was stopped", w.config.ShutdownTimeout)
+	}
+	if health != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		health.Shutdown(shutdownCtx)
+	}
+	return err
+}
+
+// consume runs the job until stop is cancelled, waiting PollInterval after runs without work or failed
+func (w *Worker) consume(stop context.Context, ctx context.Context, job Job) {
+	for stop.Err() == nil {
+		err := job.Run(ctx)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, ErrNoWork) {
+			log.Printf("job failed: %v", err)
+		}
+		select {
+		case <-stop.Done():
+		case <-time.After(w.config.PollInterval):
+		}
+	}
+}
+
+// serveHealth serves /healthz at HealthAddress, answering 200 while the worker runs its jobs and 503 otherwise
+func (w *Worker) serveHealth() *http.Server {
+	if w.config.HealthAddress == "" {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(rw http.ResponseWriter, r *http.Request) {
+		if !w.ready.Load() {
+			http.Error(rw, "stopping", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(rw, "ok")
+	})
+	server := &http.Server{Addr: w.config.HealthAddress, Handler: mux}
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("health endpoint failed: %v", err)
+		}
+	}()
+	return server
+}
+`))
+
+// initializeWorker adds the worker category and package the worker entrypoints share, unless an earlier
+// worker entrypoint added them
+func initializeWorker(module string) error {
+	exists, err := categoryExists(workerCategory)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		err = createCategoryWithOptions(workerCategory, categoryOptions{})
+		if err != nil {
+			return err
+		}
+		for _, property := range workerProperties {
+			err = property.add(workerCategory)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if _, err := os.Stat(workerPackagePath); err == nil {
+		return nil
+	}
+	err = requireModules(cronModule)
+	if err != nil {
+		return err
+	}
+	var code bytes.Buffer
+	err = workerPackage.Execute(&code, map[string]string{"Module": module})
+	if err != nil {
+		return err
+	}
+	formattedCode, err := format.Source(code.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format code: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(workerPackagePath), os.ModePerm)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(workerPackagePath, formattedCode, 0644)
+}
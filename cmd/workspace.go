@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"template/config"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+)
+
+const goWorkFileName = "go.work"
+
+// workspaceCmd groups the commands managing the Go workspace of a repository holding several projects
+var workspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manage the Go workspace of a repository holding several projects",
+	Long: `Manage the go.work of a repository holding several projects. init --workspace adds the projects
+it generates to the nearest go.work above them, and the config and add commands run from any
+directory of a project act on that project.`,
+}
+
+// workspaceInitCmd represents the workspace init command
+var workspaceInitCmd = &cobra.Command{
+	Use:   "init [module_directories]",
+	Short: "Create a go.work in the current directory",
+	Long: `Create a go.work in the current directory using the modules of the given directories. Without
+directories it uses every module found under the current directory.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if _, err := os.Stat(goWorkFileName); err == nil {
+			return fmt.Errorf("%s already exists", goWorkFileName)
+		}
+		directories := args
+		if len(directories) == 0 {
+			var err error
+			directories, err = findModuleDirectories(".")
+			if err != nil {
+				return err
+			}
+		}
+		for _, directory := range directories {
+			if _, err := os.Stat(filepath.Join(directory, goModFileName)); err != nil {
+				return fmt.Errorf("%s is not a module directory: %w", directory, err)
+			}
+		}
+		file := &modfile.WorkFile{Syntax: &modfile.FileSyntax{}}
+		err := file.AddGoStmt(config.GoVersion)
+		if err != nil {
+			return err
+		}
+		for _, directory := range directories {
+			err = useModule(file, directory, workspaceUsePath(directory))
+			if err != nil {
+				return err
+			}
+		}
+		return writeWorkFile(goWorkFileName, file)
+	},
+}
+
+// findModuleDirectories returns the directories under the directory holding a go.mod, skipping hidden
+// directories and vendor
+func findModuleDirectories(directory string) ([]string, error) {
+	var directories []string
+	err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() && path != directory && (strings.HasPrefix(entry.Name(), ".") || entry.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+		if !entry.IsDir() && entry.Name() == goModFileName {
+			directories = append(directories, filepath.Dir(path))
+		}
+		return nil
+	})
+	return directories, err
+}
+
+// workspaceUsePath turns a module directory relative to the go.work into the path of its use directive,
+// ./services/api rather than services/api
+func workspaceUsePath(directory string) string {
+	path := filepath.ToSlash(filepath.Clean(directory))
+	if path == "." || strings.HasPrefix(path, "../") {
+		return path
+	}
+	return "./" + path
+}
+
+// findWorkspace walks up from the directory to the nearest go.work and returns its path, empty when
+// there's none
+func findWorkspace(directory string) (string, error) {
+	directory, err := filepath.Abs(directory)
+	if err != nil {
+		return "", err
+	}
+	for {
+		path := filepath.Join(directory, goWorkFileName)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			return "", nil
+		}
+		directory = parent
+	}
+}
+
+// addToWorkspace adds the module of the directory to the go.work, unless it uses it already
+func addToWorkspace(workPath string, moduleDirectory string) error {
+	contents, err := os.ReadFile(workPath)
+	if err != nil {
+		return err
+	}
+	file, err := modfile.ParseWork(workPath, contents, nil)
+	if err != nil {
+		return err
+	}
+	moduleDirectory, err = filepath.Abs(moduleDirectory)
+	if err != nil {
+		return err
+	}
+	relativePath, err := filepath.Rel(filepath.Dir(workPath), moduleDirectory)
+	if err != nil {
+		return err
+	}
+	usePath := workspaceUsePath(relativePath)
+	for _, use := range file.Use {
+		if filepath.Clean(use.Path) == filepath.Clean(usePath) {
+			return nil
+		}
+	}
+	err = useModule(file, moduleDirectory, usePath)
+	if err != nil {
+		return err
+	}
+	file.SortBlocks()
+	return writeWorkFile(workPath, file)
+}
+
+// useModule adds the use directive of the module directory to the go.work and raises its go directive to
+// the module's, go refusing workspaces older than their modules
+func useModule(file *modfile.WorkFile, moduleDirectory string, usePath string) error {
+	contents, err := os.ReadFile(filepath.Join(moduleDirectory, goModFileName))
+	if err != nil {
+		return err
+	}
+	module, err := modfile.Parse(goModFileName, contents, nil)
+	if err != nil {
+		return err
+	}
+	if module.Go != nil && (file.Go == nil || goVersionLess(file.Go.Version, module.Go.Version)) {
+		err = file.AddGoStmt(module.Go.Version)
+		if err != nil {
+			return err
+		}
+	}
+	return file.AddUse(usePath, "")
+}
+
+// goVersionLess reports whether the Go version is older than the other one, the language version 1.25
+// being older than its release 1.25.0
+func goVersionLess(version string, other string) bool {
+	if comparison := semver.Compare("v"+version, "v"+other); comparison != 0 {
+		return comparison < 0
+	}
+	return len(version) < len(other)
+}
+
+func writeWorkFile(path string, file *modfile.WorkFile) error {
+	file.Cleanup()
+	return os.WriteFile(path, modfile.Format(file.Syntax), 0644)
+}
+
+func init() {
+	rootCmd.AddCommand(workspaceCmd)
+	workspaceCmd.AddCommand(workspaceInitCmd)
+}
@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlDocument is a yaml file loaded as a node tree. Editing the tree instead of the text keeps
+// comments, key ordering and the layout of untouched parts of the file.
+type yamlDocument struct {
+	root   *yaml.Node
+	indent int
+}
+
+func loadYamlDocument(filePath string) (*yamlDocument, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	document := &yamlDocument{root: &yaml.Node{}, indent: detectYamlIndent(contents)}
+	err = yaml.Unmarshal(contents, document.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+	if document.root.Kind == 0 {
+		// the parser drops the comments of documents holding nothing else, like a schema header
+		document.root = &yaml.Node{
+			Kind:        yaml.DocumentNode,
+			HeadComment: strings.TrimSpace(string(contents)),
+			Content:     []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	if document.root.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("top level of %s is not a mapping", filePath)
+	}
+	return document, nil
+}
+
+// Get returns the node stored under the path of keys
+func (d *yamlDocument) Get(path []string) (*yaml.Node, bool) {
+	node := d.root.Content[0]
+	for _, key := range path {
+		if node.Kind != yaml.MappingNode {
+			return nil, false
+		}
+		_, value := findYamlKey(node, key)
+		if value == nil {
+			return nil, false
+		}
+		node = value
+	}
+	return node, true
+}
+
+// Set inserts or updates the value under the path, creating missing intermediate mappings.
+// Comments attached to an updated value are kept.
+func (d *yamlDocument) Set(path []string, value any) error {
+	if len(path) == 0 {
+		return fmt.Errorf("empty yaml path")
+	}
+	valueNode := &yaml.Node{}
+	err := valueNode.Encode(value)
+	if err != nil {
+		return err
+	}
+	node := d.root.Content[0]
+	for i, key := range path {
+		_, existing := findYamlKey(node, key)
+		last := i == len(path)-1
+		if existing == nil {
+			if last {
+				node.Content = append(node.Content, newYamlKey(key), valueNode)
+				return nil
+			}
+			existing = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, newYamlKey(key), existing)
+		}
+		if last {
+			valueNode.HeadComment = existing.HeadComment
+			valueNode.LineComment = existing.LineComment
+			valueNode.FootComment = existing.FootComment
+			*existing = *valueNode
+			return nil
+		}
+		if existing.Kind != yaml.MappingNode {
+			return fmt.Errorf("key %s is not a mapping", strings.Join(path[:i+1], "."))
+		}
+		node = existing
+	}
+	return nil
+}
+
+// Delete removes the key under the path. Mappings left empty by the removal are removed as well.
+func (d *yamlDocument) Delete(path []string) (bool, error) {
+	if len(path) == 0 {
+		return false, fmt.Errorf("empty yaml path")
+	}
+	mapping := d.root.Content[0]
+	header := ""
+	if len(mapping.Content) > 0 {
+		header = mapping.Content[0].HeadComment
+	}
+	deleted := deleteYamlKey(mapping, path)
+	// the header of a file whose last key is removed is kept as the comment of the document
+	if deleted && len(mapping.Content) == 0 && header != "" {
+		d.root.HeadComment = joinYamlComments(d.root.HeadComment, header)
+	}
+	return deleted, nil
+}
+
+func joinYamlComments(first string, second string) string {
+	if first == "" || second == "" {
+		return first + second
+	}
+	return first + "\n" + second
+}
+
+func (d *yamlDocument) Save(filePath string) error {
+	var buf bytes.Buffer
+	if len(d.root.Content[0].Content) == 0 && d.root.Content[0].HeadComment == "" && d.root.HeadComment != "" {
+		// an empty mapping would be written as {} below the comments
+		return os.WriteFile(filePath, []byte(d.root.HeadComment+"\n"), 0644)
+	}
+	if len(d.root.Content[0].Content) > 0 || d.root.Content[0].HeadComment != "" {
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(d.indent)
+		err := encoder.Encode(d.root)
+		if err != nil {
+			return err
+		}
+		err = encoder.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(filePath, buf.Bytes(), 0644)
+}
+
+func deleteYamlKey(mapping *yaml.Node, path []string) bool {
+	if mapping.Kind != yaml.MappingNode {
+		return false
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != path[0] {
+			continue
+		}
+		if len(path) == 1 {
+			removeYamlPair(mapping, i)
+			return true
+		}
+		child := mapping.Content[i+1]
+		if !deleteYamlKey(child, path[1:]) {
+			return false
+		}
+		if len(child.Content) == 0 {
+			removeYamlPair(mapping, i)
+		}
+		return true
+	}
+	return false
+}
+
+// removeYamlPair removes the key at the index and its value. The comment heading the first key heads the
+// mapping, like the header of a file, it moves to the key coming first now.
+func removeYamlPair(mapping *yaml.Node, index int) {
+	comment := mapping.Content[index].HeadComment
+	mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+	if index == 0 && comment != "" && len(mapping.Content) > 0 {
+		mapping.Content[0].HeadComment = joinYamlComments(comment, mapping.Content[0].HeadComment)
+	}
+}
+
+func findYamlKey(mapping *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func newYamlKey(key string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}
+}
+
+// detectYamlIndent returns the indentation of the first nested line, so rewritten files keep their indentation
+func detectYamlIndent(contents []byte) int {
+	for _, line := range strings.Split(string(contents), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "-") {
+			continue
+		}
+		return len(line) - len(trimmed)
+	}
+	return 2
+}
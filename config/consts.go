@@ -2,3 +2,15 @@ package config
 
 const AppName = "template"
 const ConfigLibraryName = "github.com/spf13/viper"
+
+// GoVersion is the go directive of generated projects, the oldest Go the pinned dependencies build with
+const GoVersion = "1.25"
+
+const (
+	ConfigFileDirectory   = "config"
+	ConfigSourceDirectory = "pkg/infra/config"
+	GeneratorDirectory    = "generator"
+	ManifestFileName      = "manifest.json"
+	DefaultConfigFormat   = "yaml"
+	DefaultConfigLibrary  = "viper"
+)